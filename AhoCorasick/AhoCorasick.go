@@ -0,0 +1,203 @@
+package ahocorasick
+
+import (
+	"iter"
+	"slices"
+
+	trie "github.com/JustJ3di/Golletions/Trie"
+)
+
+// MatchKind selects which matches FindAll reports.
+type MatchKind int
+
+const (
+	// Overlapping reports every occurrence of every pattern, including
+	// occurrences that overlap or contain each other.
+	Overlapping MatchKind = iota
+	// LeftmostLongest reports non-overlapping matches, scanning left to
+	// right and preferring, at each position, the longest pattern.
+	LeftmostLongest
+)
+
+// Match is an occurrence of a pattern in the scanned text, text[Start:End].
+type Match struct {
+	Pattern    int
+	Start, End int
+}
+
+const noState = -1
+
+type state struct {
+	next    map[byte]int32
+	fail    int32
+	out     int32 // nearest state on the fail chain that ends a pattern
+	pattern int32 // pattern ending exactly here, or noState
+	depth   int32
+}
+
+// Automaton finds many patterns in one linear pass over the text.
+// It is safe for concurrent use once built.
+type Automaton struct {
+	states   []state
+	patterns []string
+	kind     MatchKind
+}
+
+// New builds an automaton over patterns. A match reports the index of its
+// pattern in the slice; if a pattern appears twice, its first index is used.
+// Empty patterns never match.
+func New(patterns []string, kind MatchKind) *Automaton {
+	a := &Automaton{patterns: slices.Clone(patterns), kind: kind}
+	a.states = append(a.states, newState(0))
+
+	for id, p := range patterns {
+		s := int32(0)
+		for i := 0; i < len(p); i++ {
+			b := p[i]
+			if kind == LeftmostLongest {
+				// This mode scans the text backwards, see leftmostLongest.
+				b = p[len(p)-1-i]
+			}
+			next, ok := a.states[s].next[b]
+			if !ok {
+				next = int32(len(a.states))
+				a.states = append(a.states, newState(int32(i+1)))
+				a.states[s].next[b] = next
+			}
+			s = next
+		}
+		if s != 0 && a.states[s].pattern == noState {
+			a.states[s].pattern = int32(id)
+		}
+	}
+
+	a.link()
+	return a
+}
+
+// FromTrie builds an automaton over the words stored in t. Pattern IDs are
+// the positions of the words in t.KeysWithPrefix("").
+func FromTrie(t *trie.Trie, kind MatchKind) *Automaton {
+	return New(t.KeysWithPrefix(""), kind)
+}
+
+func newState(depth int32) state {
+	return state{next: make(map[byte]int32), out: noState, pattern: noState, depth: depth}
+}
+
+// link computes failure and output links breadth first, so the links of
+// every shallower state are ready when a state is processed.
+func (a *Automaton) link() {
+	queue := make([]int32, 0, len(a.states))
+	for _, child := range a.states[0].next {
+		queue = append(queue, child)
+	}
+
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+
+		for b, child := range a.states[s].next {
+			f := a.states[s].fail
+			for f != 0 {
+				if _, ok := a.states[f].next[b]; ok {
+					break
+				}
+				f = a.states[f].fail
+			}
+			if next, ok := a.states[f].next[b]; ok && next != child {
+				f = next
+			}
+
+			a.states[child].fail = f
+			if a.states[f].pattern != noState {
+				a.states[child].out = f
+			} else {
+				a.states[child].out = a.states[f].out
+			}
+			queue = append(queue, child)
+		}
+	}
+}
+
+func (a *Automaton) step(s int32, b byte) int32 {
+	for {
+		if next, ok := a.states[s].next[b]; ok {
+			return next
+		}
+		if s == 0 {
+			return 0
+		}
+		s = a.states[s].fail
+	}
+}
+
+// Pattern returns the pattern with the given ID.
+func (a *Automaton) Pattern(id int) string {
+	return a.patterns[id]
+}
+
+// FindAll returns the matches in text, ordered by end offset in Overlapping
+// mode and by start offset in LeftmostLongest mode.
+func (a *Automaton) FindAll(text string) iter.Seq[Match] {
+	return func(yield func(Match) bool) {
+		if a.kind == LeftmostLongest {
+			a.leftmostLongest(text, yield)
+		} else {
+			a.overlapping(text, yield)
+		}
+	}
+}
+
+// matchesAt calls fn for every pattern ending in state s, text position end.
+func (a *Automaton) matchesAt(s int32, end int, fn func(Match) bool) bool {
+	if a.states[s].pattern == noState {
+		s = a.states[s].out
+	}
+	for ; s != noState; s = a.states[s].out {
+		st := &a.states[s]
+		if !fn(Match{Pattern: int(st.pattern), Start: end - int(st.depth), End: end}) {
+			return false
+		}
+	}
+	return true
+}
+
+func (a *Automaton) overlapping(text string, yield func(Match) bool) {
+	s := int32(0)
+	for i := 0; i < len(text); i++ {
+		s = a.step(s, text[i])
+		if !a.matchesAt(s, i+1, yield) {
+			return
+		}
+	}
+}
+
+// leftmostLongest runs the automaton, built over the reversed patterns,
+// backwards over the text. The longest output of its state at offset i is
+// then the longest pattern starting at i, so picking matches left to right
+// is a walk over those offsets that never rescans the text.
+func (a *Automaton) leftmostLongest(text string, yield func(Match) bool) {
+	longest := make([]int32, len(text))
+	s := int32(0)
+	for i := len(text) - 1; i >= 0; i-- {
+		s = a.step(s, text[i])
+		if a.states[s].pattern != noState {
+			longest[i] = s
+		} else {
+			longest[i] = a.states[s].out
+		}
+	}
+
+	for i := 0; i < len(text); {
+		if longest[i] == noState {
+			i++
+			continue
+		}
+		st := &a.states[longest[i]]
+		if !yield(Match{Pattern: int(st.pattern), Start: i, End: i + int(st.depth)}) {
+			return
+		}
+		i += int(st.depth)
+	}
+}
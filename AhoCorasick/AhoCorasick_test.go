@@ -0,0 +1,157 @@
+package ahocorasick
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	trie "github.com/JustJ3di/Golletions/Trie"
+)
+
+func randomString(r *rand.Rand, maxLen int, alphabet string) string {
+	b := make([]byte, r.Intn(maxLen+1))
+	for i := range b {
+		b[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return string(b)
+}
+
+// firstIDs maps each non-empty pattern to its first index.
+func firstIDs(patterns []string) map[string]int {
+	ids := map[string]int{}
+	for i, p := range patterns {
+		if _, ok := ids[p]; !ok && p != "" {
+			ids[p] = i
+		}
+	}
+	return ids
+}
+
+// naiveOverlapping checks every pattern at every end offset, longest first.
+func naiveOverlapping(patterns []string, text string) []Match {
+	var ms []Match
+	for end := 1; end <= len(text); end++ {
+		var here []Match
+		for p, id := range firstIDs(patterns) {
+			if strings.HasSuffix(text[:end], p) {
+				here = append(here, Match{id, end - len(p), end})
+			}
+		}
+		slices.SortFunc(here, func(x, y Match) int { return x.Start - y.Start })
+		ms = append(ms, here...)
+	}
+	return ms
+}
+
+// naiveLeftmostLongest takes, from each position on, the longest pattern
+// starting there, and resumes after it.
+func naiveLeftmostLongest(patterns []string, text string) []Match {
+	var ms []Match
+	for start := 0; start < len(text); {
+		best := Match{Pattern: -1}
+		for p, id := range firstIDs(patterns) {
+			if strings.HasPrefix(text[start:], p) && len(p) > best.End-best.Start {
+				best = Match{id, start, start + len(p)}
+			}
+		}
+		if best.Pattern < 0 {
+			start++
+			continue
+		}
+		ms = append(ms, best)
+		start = best.End
+	}
+	return ms
+}
+
+func TestAgainstNaiveScan(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for range 500 {
+		alphabet := "ab"
+		if r.Intn(2) == 0 {
+			alphabet = "abcd"
+		}
+		patterns := make([]string, 1+r.Intn(8))
+		for i := range patterns {
+			patterns[i] = randomString(r, 4, alphabet)
+		}
+		text := randomString(r, 40, alphabet)
+
+		got := slices.Collect(New(patterns, Overlapping).FindAll(text))
+		if want := naiveOverlapping(patterns, text); !slices.Equal(got, want) {
+			t.Fatalf("Overlapping %q in %q:\n got %v\nwant %v", patterns, text, got, want)
+		}
+		got = slices.Collect(New(patterns, LeftmostLongest).FindAll(text))
+		if want := naiveLeftmostLongest(patterns, text); !slices.Equal(got, want) {
+			t.Fatalf("LeftmostLongest %q in %q:\n got %v\nwant %v", patterns, text, got, want)
+		}
+	}
+}
+
+// TestLeftmostLongestIsLinear scans texts where a long pattern stays alive
+// to the end, or where every offset starts many matches. A scan that keeps
+// rescanning or buffering matches is quadratic on them.
+func TestLeftmostLongestIsLinear(t *testing.T) {
+	const n = 1 << 18
+	text := strings.Repeat("a", n)
+	var runs []string
+	for k := 1; k <= 64; k++ {
+		runs = append(runs, strings.Repeat("a", k))
+	}
+	cases := []struct {
+		patterns []string
+		matches  int
+	}{
+		{[]string{"a", text + "b"}, n},
+		{runs, n / 64},
+	}
+
+	start := time.Now()
+	for _, c := range cases {
+		count := 0
+		for m := range New(c.patterns, LeftmostLongest).FindAll(text) {
+			if m.Start != count*(n/c.matches) {
+				t.Fatalf("match %d starts at %d", count, m.Start)
+			}
+			count++
+		}
+		if count != c.matches {
+			t.Fatalf("%d matches, want %d", count, c.matches)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("scanning %d bytes took %v", n, elapsed)
+	}
+}
+
+func TestFromTrie(t *testing.T) {
+	tr := trie.New()
+	for _, w := range []string{"he", "she", "his", "hers"} {
+		tr.Insert(w)
+	}
+	a := FromTrie(tr, Overlapping)
+	var found []string
+	for m := range a.FindAll("ushers") {
+		found = append(found, a.Pattern(m.Pattern))
+	}
+	if want := []string{"she", "he", "hers"}; !slices.Equal(found, want) {
+		t.Errorf("FindAll(ushers) = %q, want %q", found, want)
+	}
+}
+
+func TestFindAllStops(t *testing.T) {
+	for _, kind := range []MatchKind{Overlapping, LeftmostLongest} {
+		n := 0
+		for range New([]string{"a"}, kind).FindAll("aaaa") {
+			n++
+			if n == 2 {
+				break
+			}
+		}
+		if n != 2 {
+			t.Errorf("kind %d: loop ran %d times", kind, n)
+		}
+	}
+}
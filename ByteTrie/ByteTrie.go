@@ -0,0 +1,197 @@
+package bytetrie
+
+import (
+	"bytes"
+	"math/bits"
+)
+
+// A node keeps its labels in a small sorted array until it has more than
+// smallLimit children, then switches to a 256-bit bitmap. In both forms the
+// children slice is ordered by label, so the bitmap form finds a child's index
+// by counting the bits set below its label.
+const smallLimit = 16
+
+type bytenode struct {
+	labels   []byte
+	bitmap   *[4]uint64
+	children []*bytenode
+	end      bool
+}
+
+// Trie is a prefix tree over byte strings. Unlike trie.Trie it does not decode
+// runes, so it can index arbitrary binary keys such as hashes and IDs.
+type Trie struct {
+	root *bytenode
+}
+
+func New() *Trie {
+	return &Trie{root: &bytenode{}}
+}
+
+// index returns the position of label b in n.children and whether it exists.
+// If it does not exist, the position is where it would be inserted.
+func (n *bytenode) index(b byte) (int, bool) {
+	if n.bitmap != nil {
+		word, bit := b>>6, uint64(1)<<(b&63)
+		i := 0
+		for w := byte(0); w < word; w++ {
+			i += bits.OnesCount64(n.bitmap[w])
+		}
+		i += bits.OnesCount64(n.bitmap[word] & (bit - 1))
+		return i, n.bitmap[word]&bit != 0
+	}
+
+	for i, l := range n.labels {
+		if l >= b {
+			return i, l == b
+		}
+	}
+	return len(n.labels), false
+}
+
+func (n *bytenode) child(b byte) *bytenode {
+	if i, ok := n.index(b); ok {
+		return n.children[i]
+	}
+	return nil
+}
+
+func (n *bytenode) addChild(b byte, child *bytenode) {
+	i, _ := n.index(b)
+	n.children = append(n.children, nil)
+	copy(n.children[i+1:], n.children[i:])
+	n.children[i] = child
+
+	if n.bitmap != nil {
+		n.bitmap[b>>6] |= 1 << (b & 63)
+		return
+	}
+	n.labels = append(n.labels, 0)
+	copy(n.labels[i+1:], n.labels[i:])
+	n.labels[i] = b
+
+	if len(n.labels) > smallLimit {
+		n.bitmap = new([4]uint64)
+		for _, l := range n.labels {
+			n.bitmap[l>>6] |= 1 << (l & 63)
+		}
+		n.labels = nil
+	}
+}
+
+func (n *bytenode) removeChild(b byte) {
+	i, ok := n.index(b)
+	if !ok {
+		return
+	}
+	n.children = append(n.children[:i], n.children[i+1:]...)
+
+	if n.bitmap == nil {
+		n.labels = append(n.labels[:i], n.labels[i+1:]...)
+		return
+	}
+	n.bitmap[b>>6] &^= 1 << (b & 63)
+	// Go back to the array form only well below the limit, so a node that
+	// hovers around it doesn't convert back and forth on every update.
+	if len(n.children) <= smallLimit/2 {
+		n.labels = n.eachLabel(nil)
+		n.bitmap = nil
+	}
+}
+
+// eachLabel appends the labels of n, in order, to dst.
+func (n *bytenode) eachLabel(dst []byte) []byte {
+	if n.bitmap == nil {
+		return append(dst, n.labels...)
+	}
+	for w, word := range n.bitmap {
+		for word != 0 {
+			dst = append(dst, byte(w<<6|bits.TrailingZeros64(word)))
+			word &= word - 1
+		}
+	}
+	return dst
+}
+
+func (t *Trie) walk(key []byte) *bytenode {
+	curr := t.root
+	for _, b := range key {
+		if curr = curr.child(b); curr == nil {
+			return nil
+		}
+	}
+	return curr
+}
+
+func (t *Trie) Insert(key []byte) {
+	curr := t.root
+	for _, b := range key {
+		next := curr.child(b)
+		if next == nil {
+			next = &bytenode{}
+			curr.addChild(b, next)
+		}
+		curr = next
+	}
+	curr.end = true
+}
+
+func (t *Trie) Search(key []byte) bool {
+	n := t.walk(key)
+	return n != nil && n.end
+}
+
+// StartsWith reports whether some stored key begins with prefix.
+func (t *Trie) StartsWith(prefix []byte) bool {
+	return t.walk(prefix) != nil
+}
+
+// Delete removes key and prunes the nodes left without keys.
+// It returns false if key was not stored.
+func (t *Trie) Delete(key []byte) bool {
+	path := make([]*bytenode, 0, len(key)+1)
+	path = append(path, t.root)
+	curr := t.root
+	for _, b := range key {
+		if curr = curr.child(b); curr == nil {
+			return false
+		}
+		path = append(path, curr)
+	}
+	if !curr.end {
+		return false
+	}
+	curr.end = false
+
+	for i := len(key) - 1; i >= 0; i-- {
+		n := path[i+1]
+		if n.end || len(n.children) > 0 {
+			break
+		}
+		path[i].removeChild(key[i])
+	}
+	return true
+}
+
+// KeysWithPrefix returns every stored key starting with prefix, in
+// lexicographic order.
+func (t *Trie) KeysWithPrefix(prefix []byte) [][]byte {
+	n := t.walk(prefix)
+	if n == nil {
+		return nil
+	}
+
+	var keys [][]byte
+	var collect func(n *bytenode, path []byte)
+	collect = func(n *bytenode, path []byte) {
+		if n.end {
+			keys = append(keys, bytes.Clone(path))
+		}
+		var scratch [smallLimit]byte
+		for i, l := range n.eachLabel(scratch[:0]) {
+			collect(n.children[i], append(path, l))
+		}
+	}
+	collect(n, bytes.Clone(prefix))
+	return keys
+}
@@ -0,0 +1,198 @@
+package bytetrie
+
+import (
+	"bytes"
+	"math/rand"
+	"slices"
+	"testing"
+
+	trie "github.com/JustJ3di/Golletions/Trie"
+)
+
+// checkNode verifies that n's labels, in either form, are sorted, match its
+// children one to one, and equal want.
+func checkNode(t *testing.T, n *bytenode, want []byte) {
+	t.Helper()
+	got := n.eachLabel(nil)
+	if !bytes.Equal(got, want) {
+		t.Fatalf("labels = %v, want %v", got, want)
+	}
+	if len(n.children) != len(want) {
+		t.Fatalf("%d children for %d labels", len(n.children), len(want))
+	}
+	for i, l := range want {
+		if n.child(l) != n.children[i] {
+			t.Fatalf("child(%d) is not children[%d]", l, i)
+		}
+	}
+	if n.bitmap != nil && n.labels != nil {
+		t.Fatal("node has both a bitmap and a label array")
+	}
+}
+
+func TestChildFormSwitch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	n := &bytenode{}
+	var want []byte
+
+	// Grow past smallLimit: the node must switch to the bitmap exactly when
+	// it gets its smallLimit+1-th child.
+	for _, l := range r.Perm(256)[:smallLimit+8] {
+		b := byte(l)
+		n.addChild(b, &bytenode{})
+		i, _ := slices.BinarySearch(want, b)
+		want = slices.Insert(want, i, b)
+		checkNode(t, n, want)
+		if isBitmap := n.bitmap != nil; isBitmap != (len(want) > smallLimit) {
+			t.Fatalf("%d children: bitmap = %v", len(want), isBitmap)
+		}
+	}
+
+	// Shrink: the bitmap stays until smallLimit/2 children are left.
+	for len(want) > 0 {
+		b := want[r.Intn(len(want))]
+		wasBitmap := n.bitmap != nil
+		n.removeChild(b)
+		i, _ := slices.BinarySearch(want, b)
+		want = slices.Delete(want, i, i+1)
+		checkNode(t, n, want)
+		switch {
+		case wasBitmap && len(want) > smallLimit/2 && n.bitmap == nil:
+			t.Fatalf("%d children: switched back to the array too early", len(want))
+		case len(want) <= smallLimit/2 && n.bitmap != nil:
+			t.Fatalf("%d children: still a bitmap", len(want))
+		}
+	}
+
+	// Removing a missing label is a no-op in both forms.
+	n.removeChild(7)
+	checkNode(t, n, nil)
+}
+
+func TestAgainstMap(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for round := 0; round < 100; round++ {
+		tr := New()
+		ref := map[string]bool{}
+		// Short keys over a wide alphabet so nodes cross smallLimit often.
+		key := func() []byte {
+			k := make([]byte, r.Intn(3))
+			for i := range k {
+				k[i] = byte(r.Intn(40) * 6)
+			}
+			return k
+		}
+		for step := 0; step < 300; step++ {
+			k := key()
+			if r.Intn(3) == 0 {
+				if got := tr.Delete(k); got != ref[string(k)] {
+					t.Fatalf("Delete(%v) = %v", k, got)
+				}
+				delete(ref, string(k))
+			} else {
+				tr.Insert(k)
+				ref[string(k)] = true
+			}
+
+			p := key()
+			if got := tr.Search(p); got != ref[string(p)] {
+				t.Fatalf("Search(%v) = %v", p, got)
+			}
+			var want [][]byte
+			for k := range ref {
+				if bytes.HasPrefix([]byte(k), p) {
+					want = append(want, []byte(k))
+				}
+			}
+			slices.SortFunc(want, bytes.Compare)
+			got := tr.KeysWithPrefix(p)
+			if !slices.EqualFunc(got, want, bytes.Equal) {
+				t.Fatalf("KeysWithPrefix(%v) = %v, want %v", p, got, want)
+			}
+			if tr.StartsWith(p) != (len(want) > 0 || len(p) == 0) {
+				t.Fatalf("StartsWith(%v) wrong", p)
+			}
+		}
+	}
+}
+
+// randomKeys returns n random binary keys of the given length, as hashes
+// or binary IDs would look.
+func randomKeys(n, length int) [][]byte {
+	r := rand.New(rand.NewSource(3))
+	keys := make([][]byte, n)
+	for i := range keys {
+		keys[i] = make([]byte, length)
+		r.Read(keys[i])
+	}
+	return keys
+}
+
+// fanOutKeys returns keys whose first two bytes take every ASCII value, so
+// the top nodes have 128 children and use the bitmap form. Staying in ASCII
+// keeps the keys valid UTF-8, so both tries store the same set.
+func fanOutKeys() [][]byte {
+	var keys [][]byte
+	for a := 0; a < 128; a++ {
+		for b := 0; b < 128; b++ {
+			keys = append(keys, []byte{byte(a), byte(b), 'x'})
+		}
+	}
+	return keys
+}
+
+// The rune trie gets the same keys as strings, the way binary keys would
+// have to be stored in it. Note that it decodes every invalid UTF-8 byte as
+// U+FFFD, so on random binary keys it merges keys the byte trie keeps apart.
+func benchmarkInsert(b *testing.B, keys [][]byte) {
+	b.Run("bytetrie", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			t := New()
+			for _, k := range keys {
+				t.Insert(k)
+			}
+		}
+	})
+	b.Run("trie", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			t := trie.New()
+			for _, k := range keys {
+				t.Insert(string(k))
+			}
+		}
+	})
+}
+
+func benchmarkSearch(b *testing.B, keys [][]byte) {
+	b.Run("bytetrie", func(b *testing.B) {
+		t := New()
+		for _, k := range keys {
+			t.Insert(k)
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := range b.N {
+			t.Search(keys[i%len(keys)])
+		}
+	})
+	b.Run("trie", func(b *testing.B) {
+		t := trie.New()
+		strs := make([]string, len(keys))
+		for i, k := range keys {
+			strs[i] = string(k)
+			t.Insert(strs[i])
+		}
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := range b.N {
+			t.Search(strs[i%len(strs)])
+		}
+	})
+}
+
+func BenchmarkInsertRandom(b *testing.B) { benchmarkInsert(b, randomKeys(10000, 16)) }
+func BenchmarkSearchRandom(b *testing.B) { benchmarkSearch(b, randomKeys(10000, 16)) }
+func BenchmarkInsertFanOut(b *testing.B) { benchmarkInsert(b, fanOutKeys()) }
+func BenchmarkSearchFanOut(b *testing.B) { benchmarkSearch(b, fanOutKeys()) }
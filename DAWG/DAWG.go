@@ -0,0 +1,264 @@
+package dawg
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+	"unicode/utf8"
+)
+
+type edge struct {
+	label rune
+	to    *dawgnode
+}
+
+type dawgnode struct {
+	edges []edge // sorted by label
+	final bool
+	words int // words accepted from this node, used for perfect hashing
+	id    int
+}
+
+// DAWG is a minimal acyclic finite-state automaton: a trie in which equal
+// subtrees, and so shared suffixes, are stored once. It is read-only; build
+// one with a Builder.
+type DAWG struct {
+	root *dawgnode
+}
+
+var (
+	ErrOrder    = errors.New("dawg: words must be inserted in strictly increasing order")
+	ErrFinished = errors.New("dawg: builder already finished")
+)
+
+type pending struct {
+	parent *dawgnode
+	child  *dawgnode
+}
+
+// Builder constructs a DAWG from sorted words with Daciuk's incremental
+// algorithm: only the path of the last word is kept unminimized, so memory
+// stays proportional to the final automaton.
+type Builder struct {
+	root      *dawgnode
+	register  map[string]*dawgnode
+	unchecked []pending
+	prev      string
+	prevRunes []rune
+	count     int
+	nextID    int
+	finished  bool
+}
+
+func NewBuilder() *Builder {
+	return &Builder{root: &dawgnode{}, register: make(map[string]*dawgnode), nextID: 1}
+}
+
+// Insert adds word, which must sort after every word inserted before.
+func (b *Builder) Insert(word string) error {
+	if b.finished {
+		return ErrFinished
+	}
+	if b.count > 0 && word <= b.prev {
+		return ErrOrder
+	}
+
+	runes := []rune(word)
+	common := 0
+	for common < len(runes) && common < len(b.prevRunes) && runes[common] == b.prevRunes[common] {
+		common++
+	}
+	// The previous word's path past the shared prefix will never change
+	// again, so it can be merged with equivalent nodes.
+	b.minimize(common)
+
+	curr := b.root
+	if len(b.unchecked) > 0 {
+		curr = b.unchecked[len(b.unchecked)-1].child
+	}
+	for _, ch := range runes[common:] {
+		next := &dawgnode{id: b.nextID}
+		b.nextID++
+		curr.edges = append(curr.edges, edge{label: ch, to: next})
+		b.unchecked = append(b.unchecked, pending{parent: curr, child: next})
+		curr = next
+	}
+	curr.final = true
+
+	b.prev, b.prevRunes = word, runes
+	b.count++
+	return nil
+}
+
+// minimize replaces the unchecked nodes deeper than depth with their
+// registered equivalent, or registers them.
+func (b *Builder) minimize(depth int) {
+	for i := len(b.unchecked) - 1; i >= depth; i-- {
+		p := b.unchecked[i]
+		sig := p.child.signature()
+		if same, ok := b.register[sig]; ok {
+			p.parent.edges[len(p.parent.edges)-1].to = same
+		} else {
+			b.register[sig] = p.child
+		}
+	}
+	b.unchecked = b.unchecked[:depth]
+}
+
+// signature identifies a node by its right language: whether it is final and
+// where each edge leads. Children are already minimized, so their ids are
+// enough.
+func (n *dawgnode) signature() string {
+	buf := make([]byte, 0, 1+len(n.edges)*2*binary.MaxVarintLen32)
+	if n.final {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	for _, e := range n.edges {
+		buf = binary.AppendUvarint(buf, uint64(e.label))
+		buf = binary.AppendUvarint(buf, uint64(e.to.id))
+	}
+	return string(buf)
+}
+
+// Finish minimizes the remaining path and returns the DAWG. The builder can
+// not be used afterwards.
+func (b *Builder) Finish() *DAWG {
+	if !b.finished {
+		b.minimize(0)
+		b.register = nil
+		b.finished = true
+		countWords(b.root, make(map[*dawgnode]bool))
+	}
+	return &DAWG{root: b.root}
+}
+
+func countWords(n *dawgnode, done map[*dawgnode]bool) int {
+	if done[n] {
+		return n.words
+	}
+	n.words = 0
+	if n.final {
+		n.words = 1
+	}
+	for _, e := range n.edges {
+		n.words += countWords(e.to, done)
+	}
+	done[n] = true
+	return n.words
+}
+
+func (n *dawgnode) child(ch rune) *dawgnode {
+	i, ok := slices.BinarySearchFunc(n.edges, ch, func(e edge, ch rune) int {
+		return int(e.label - ch)
+	})
+	if !ok {
+		return nil
+	}
+	return n.edges[i].to
+}
+
+func (d *DAWG) find(key string) *dawgnode {
+	curr := d.root
+	for _, ch := range key {
+		if curr = curr.child(ch); curr == nil {
+			return nil
+		}
+	}
+	return curr
+}
+
+func (d *DAWG) Search(key string) bool {
+	n := d.find(key)
+	return n != nil && n.final
+}
+
+// StartsWith reports whether some stored word begins with prefix.
+func (d *DAWG) StartsWith(prefix string) bool {
+	return d.find(prefix) != nil
+}
+
+// KeysWithPrefix returns every stored word starting with prefix, sorted.
+func (d *DAWG) KeysWithPrefix(prefix string) []string {
+	n := d.find(prefix)
+	if n == nil {
+		return nil
+	}
+
+	words := make([]string, 0, n.words)
+	var collect func(n *dawgnode, path []rune)
+	collect = func(n *dawgnode, path []rune) {
+		if n.final {
+			words = append(words, string(path))
+		}
+		for _, e := range n.edges {
+			collect(e.to, append(path, e.label))
+		}
+	}
+	collect(n, []rune(prefix))
+	return words
+}
+
+// Len returns the number of stored words.
+func (d *DAWG) Len() int {
+	return d.root.words
+}
+
+// Index returns the position of word in the sorted word list, a perfect hash
+// in the range [0, Len()). The second result is false if word is not stored.
+func (d *DAWG) Index(word string) (int, bool) {
+	idx := 0
+	curr := d.root
+	for _, ch := range word {
+		if curr.final {
+			idx++
+		}
+		next := (*dawgnode)(nil)
+		for _, e := range curr.edges {
+			if e.label == ch {
+				next = e.to
+				break
+			}
+			if e.label > ch {
+				break
+			}
+			idx += e.to.words
+		}
+		if next == nil {
+			return 0, false
+		}
+		curr = next
+	}
+	if !curr.final {
+		return 0, false
+	}
+	return idx, true
+}
+
+// Word returns the word at position idx of the sorted word list, the inverse
+// of Index.
+func (d *DAWG) Word(idx int) (string, bool) {
+	if idx < 0 || idx >= d.Len() {
+		return "", false
+	}
+
+	var buf []byte
+	curr := d.root
+	for {
+		if curr.final {
+			if idx == 0 {
+				return string(buf), true
+			}
+			idx--
+		}
+		for _, e := range curr.edges {
+			if idx < e.to.words {
+				buf = utf8.AppendRune(buf, e.label)
+				curr = e.to
+				break
+			}
+			idx -= e.to.words
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package dawg
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func randomWords(r *rand.Rand, n int, alphabet string) []string {
+	runes := []rune(alphabet)
+	seen := map[string]bool{}
+	var words []string
+	for len(words) < n {
+		w := make([]rune, r.Intn(8))
+		for i := range w {
+			w[i] = runes[r.Intn(len(runes))]
+		}
+		if !seen[string(w)] {
+			seen[string(w)] = true
+			words = append(words, string(w))
+		}
+	}
+	slices.Sort(words)
+	return words
+}
+
+func build(t *testing.T, words []string) *DAWG {
+	t.Helper()
+	b := NewBuilder()
+	for _, w := range words {
+		if err := b.Insert(w); err != nil {
+			t.Fatalf("Insert(%q): %v", w, err)
+		}
+	}
+	return b.Finish()
+}
+
+// minimalStates counts the distinct right languages of the prefixes of
+// words, which is the number of states of the minimal automaton.
+func minimalStates(words []string) int {
+	right := map[string][]string{}
+	for _, w := range words {
+		runes := []rune(w)
+		for i := 0; i <= len(runes); i++ {
+			p := string(runes[:i])
+			right[p] = append(right[p], string(runes[i:]))
+		}
+	}
+	langs := map[string]bool{}
+	for _, suffixes := range right {
+		slices.Sort(suffixes)
+		langs[strings.Join(suffixes, "\x00")] = true
+	}
+	return len(langs)
+}
+
+func countNodes(d *DAWG) int {
+	seen := map[*dawgnode]bool{}
+	var walk func(n *dawgnode)
+	walk = func(n *dawgnode) {
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+		for _, e := range n.edges {
+			walk(e.to)
+		}
+	}
+	walk(d.root)
+	return len(seen)
+}
+
+func TestAgainstSortedList(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, alphabet := range []string{"ab", "abcé", "日本語"} {
+		words := randomWords(r, 200, alphabet)
+		d := build(t, words)
+
+		if d.Len() != len(words) {
+			t.Fatalf("Len() = %d, want %d", d.Len(), len(words))
+		}
+		if got, want := countNodes(d), minimalStates(words); got != want {
+			t.Fatalf("%d nodes, the minimal automaton has %d", got, want)
+		}
+		for i, w := range words {
+			if !d.Search(w) {
+				t.Fatalf("Search(%q) = false", w)
+			}
+			if idx, ok := d.Index(w); !ok || idx != i {
+				t.Fatalf("Index(%q) = %d, %v; want %d", w, idx, ok, i)
+			}
+			if got, ok := d.Word(i); !ok || got != w {
+				t.Fatalf("Word(%d) = %q, %v; want %q", i, got, ok, w)
+			}
+		}
+
+		for _, q := range randomWords(r, 200, alphabet+"z") {
+			_, stored := slices.BinarySearch(words, q)
+			if d.Search(q) != stored {
+				t.Fatalf("Search(%q) = %v", q, !stored)
+			}
+			if _, ok := d.Index(q); ok != stored {
+				t.Fatalf("Index(%q) found = %v", q, ok)
+			}
+			var want []string
+			for _, w := range words {
+				if strings.HasPrefix(w, q) {
+					want = append(want, w)
+				}
+			}
+			if got := d.KeysWithPrefix(q); !slices.Equal(got, want) {
+				t.Fatalf("KeysWithPrefix(%q) = %q, want %q", q, got, want)
+			}
+			if d.StartsWith(q) != (len(want) > 0) {
+				t.Fatalf("StartsWith(%q) = %v", q, len(want) == 0)
+			}
+		}
+		for _, idx := range []int{-1, len(words)} {
+			if _, ok := d.Word(idx); ok {
+				t.Fatalf("Word(%d) succeeded", idx)
+			}
+		}
+	}
+}
+
+func TestBuilderErrors(t *testing.T) {
+	b := NewBuilder()
+	b.Insert("b")
+	for _, w := range []string{"a", "b"} {
+		if err := b.Insert(w); err != ErrOrder {
+			t.Errorf("Insert(%q) after b = %v, want ErrOrder", w, err)
+		}
+	}
+	d := b.Finish()
+	if b.Finish().Len() != d.Len() {
+		t.Error("a second Finish changed the DAWG")
+	}
+	if err := b.Insert("c"); err != ErrFinished {
+		t.Errorf("Insert after Finish = %v, want ErrFinished", err)
+	}
+
+	empty := NewBuilder().Finish()
+	if empty.Len() != 0 || empty.Search("") || len(empty.KeysWithPrefix("")) != 0 {
+		t.Error("empty DAWG is not empty")
+	}
+}
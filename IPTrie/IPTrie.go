@@ -0,0 +1,229 @@
+package iptrie
+
+import (
+	"errors"
+	"iter"
+	"net/netip"
+)
+
+var ErrInvalidPrefix = errors.New("iptrie: invalid prefix")
+
+// ipnode sits at depth d of a binary trie: the path from the root spells the
+// first d bits of an address. It holds a value if a prefix of length d was
+// inserted there.
+type ipnode[V any] struct {
+	child  [2]*ipnode[V]
+	prefix netip.Prefix
+	set    bool
+	value  V
+}
+
+// Table maps IP prefixes to values and answers longest-prefix-match queries.
+// IPv4 and IPv6 prefixes live in separate tries.
+type Table[V any] struct {
+	v4, v6 *ipnode[V]
+	len    int
+}
+
+func New[V any]() *Table[V] {
+	return &Table[V]{v4: &ipnode[V]{}, v6: &ipnode[V]{}}
+}
+
+func (t *Table[V]) root(a netip.Addr) *ipnode[V] {
+	if a.Is4() {
+		return t.v4
+	}
+	return t.v6
+}
+
+// canonical masks p and turns an IPv4-mapped IPv6 prefix into the IPv4
+// prefix it covers, as Lookup does with addresses. A mapped prefix shorter
+// than the mapping itself, /96, covers no IPv4 prefix and is rejected.
+func canonical(p netip.Prefix) (netip.Prefix, bool) {
+	if !p.IsValid() {
+		return p, false
+	}
+	if p.Addr().Is4In6() {
+		if p.Bits() < 96 {
+			return p, false
+		}
+		p = netip.PrefixFrom(p.Addr().Unmap(), p.Bits()-96)
+	}
+	return p.Masked(), true
+}
+
+// bit returns bit i of a, counting from the most significant.
+func bit(a []byte, i int) int {
+	return int(a[i/8]>>(7-i%8)) & 1
+}
+
+// Insert stores v for p, replacing the previous value if p was already
+// present. Host bits of p are ignored: 10.1.2.3/8 is stored as 10.0.0.0/8.
+// IPv4-mapped IPv6 prefixes are stored as IPv4, so ::ffff:10.0.0.0/104 is
+// 10.0.0.0/8; every method reads them that way.
+func (t *Table[V]) Insert(p netip.Prefix, v V) error {
+	p, ok := canonical(p)
+	if !ok {
+		return ErrInvalidPrefix
+	}
+
+	addr := p.Addr().AsSlice()
+	curr := t.root(p.Addr())
+	for i := 0; i < p.Bits(); i++ {
+		b := bit(addr, i)
+		if curr.child[b] == nil {
+			curr.child[b] = &ipnode[V]{}
+		}
+		curr = curr.child[b]
+	}
+	if !curr.set {
+		t.len++
+	}
+	curr.prefix, curr.set, curr.value = p, true, v
+	return nil
+}
+
+// Delete removes p and prunes the branches left empty. It returns false if p
+// was not stored.
+func (t *Table[V]) Delete(p netip.Prefix) bool {
+	p, ok := canonical(p)
+	if !ok {
+		return false
+	}
+
+	addr := p.Addr().AsSlice()
+	path := make([]*ipnode[V], 0, p.Bits()+1)
+	curr := t.root(p.Addr())
+	path = append(path, curr)
+	for i := 0; i < p.Bits(); i++ {
+		if curr = curr.child[bit(addr, i)]; curr == nil {
+			return false
+		}
+		path = append(path, curr)
+	}
+	if !curr.set {
+		return false
+	}
+
+	var zero V
+	curr.prefix, curr.set, curr.value = netip.Prefix{}, false, zero
+	t.len--
+
+	for i := p.Bits() - 1; i >= 0; i-- {
+		n := path[i+1]
+		if n.set || n.child[0] != nil || n.child[1] != nil {
+			break
+		}
+		path[i].child[bit(addr, i)] = nil
+	}
+	return true
+}
+
+// Get returns the value stored for exactly p.
+func (t *Table[V]) Get(p netip.Prefix) (V, bool) {
+	var zero V
+	p, ok := canonical(p)
+	if !ok {
+		return zero, false
+	}
+
+	addr := p.Addr().AsSlice()
+	curr := t.root(p.Addr())
+	for i := 0; i < p.Bits() && curr != nil; i++ {
+		curr = curr.child[bit(addr, i)]
+	}
+	if curr == nil || !curr.set {
+		return zero, false
+	}
+	return curr.value, true
+}
+
+// Lookup returns the longest stored prefix containing addr, and its value.
+// IPv4-mapped IPv6 addresses are looked up as IPv4.
+func (t *Table[V]) Lookup(addr netip.Addr) (netip.Prefix, V, bool) {
+	var zero V
+	if !addr.IsValid() {
+		return netip.Prefix{}, zero, false
+	}
+	addr = addr.Unmap()
+
+	var best *ipnode[V]
+	a := addr.AsSlice()
+	curr := t.root(addr)
+	for i := 0; curr != nil; i++ {
+		if curr.set {
+			best = curr
+		}
+		if i == addr.BitLen() {
+			break
+		}
+		curr = curr.child[bit(a, i)]
+	}
+	if best == nil {
+		return netip.Prefix{}, zero, false
+	}
+	return best.prefix, best.value, true
+}
+
+// Covering returns the stored prefixes that contain p, p itself included,
+// from the shortest to the longest.
+func (t *Table[V]) Covering(p netip.Prefix) iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		p, ok := canonical(p)
+		if !ok {
+			return
+		}
+
+		addr := p.Addr().AsSlice()
+		curr := t.root(p.Addr())
+		for i := 0; curr != nil; i++ {
+			if curr.set && !yield(curr.prefix, curr.value) {
+				return
+			}
+			if i == p.Bits() {
+				return
+			}
+			curr = curr.child[bit(addr, i)]
+		}
+	}
+}
+
+// Covered returns the stored prefixes contained in p, p itself included. A
+// prefix is always returned before the prefixes it contains.
+func (t *Table[V]) Covered(p netip.Prefix) iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		p, ok := canonical(p)
+		if !ok {
+			return
+		}
+
+		addr := p.Addr().AsSlice()
+		curr := t.root(p.Addr())
+		for i := 0; i < p.Bits() && curr != nil; i++ {
+			curr = curr.child[bit(addr, i)]
+		}
+		walk(curr, yield)
+	}
+}
+
+func walk[V any](n *ipnode[V], yield func(netip.Prefix, V) bool) bool {
+	if n == nil {
+		return true
+	}
+	if n.set && !yield(n.prefix, n.value) {
+		return false
+	}
+	return walk(n.child[0], yield) && walk(n.child[1], yield)
+}
+
+// All returns every stored prefix, IPv4 first.
+func (t *Table[V]) All() iter.Seq2[netip.Prefix, V] {
+	return func(yield func(netip.Prefix, V) bool) {
+		_ = walk(t.v4, yield) && walk(t.v6, yield)
+	}
+}
+
+// Len returns the number of stored prefixes.
+func (t *Table[V]) Len() int {
+	return t.len
+}
@@ -0,0 +1,191 @@
+package iptrie
+
+import (
+	"cmp"
+	"iter"
+	"math/rand"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+// randomAddr returns an address in 10.0.0.0/14 or 2001:db8::/44, so that
+// random prefixes overlap often.
+func randomAddr(r *rand.Rand) netip.Addr {
+	if r.Intn(2) == 0 {
+		return netip.AddrFrom4([4]byte{10, byte(r.Intn(4)), byte(r.Intn(256)), byte(r.Intn(256))})
+	}
+	a := [16]byte{0x20, 0x01, 0x0d, 0xb8, 0, byte(r.Intn(16))}
+	for i := 6; i < 16; i++ {
+		a[i] = byte(r.Intn(256))
+	}
+	return netip.AddrFrom16(a)
+}
+
+// randomPrefix returns a prefix with its host bits set: the table masks them.
+func randomPrefix(r *rand.Rand) netip.Prefix {
+	a := randomAddr(r)
+	// Mostly short lengths, so prefixes nest; host routes too.
+	bits := r.Intn(a.BitLen()/4 + 1)
+	if r.Intn(8) == 0 {
+		bits = a.BitLen()
+	}
+	return netip.PrefixFrom(a, bits)
+}
+
+// byAddr is the order of a preorder walk: by address, then by length.
+func byAddr(p, q netip.Prefix) int {
+	if c := p.Addr().Compare(q.Addr()); c != 0 {
+		return c
+	}
+	return cmp.Compare(p.Bits(), q.Bits())
+}
+
+func collect[V any](seq iter.Seq2[netip.Prefix, V]) []netip.Prefix {
+	var ps []netip.Prefix
+	for p := range seq {
+		ps = append(ps, p)
+	}
+	return ps
+}
+
+func TestAgainstBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	tbl := New[int]()
+	model := map[netip.Prefix]int{}
+
+	for step := range 3000 {
+		p := randomPrefix(r)
+		if r.Intn(3) == 0 {
+			_, ok := model[p.Masked()]
+			if got := tbl.Delete(p); got != ok {
+				t.Fatalf("Delete(%v) = %v, want %v", p, got, ok)
+			}
+			delete(model, p.Masked())
+		} else {
+			if err := tbl.Insert(p, step); err != nil {
+				t.Fatal(err)
+			}
+			model[p.Masked()] = step
+		}
+		if tbl.Len() != len(model) {
+			t.Fatalf("Len() = %d, want %d", tbl.Len(), len(model))
+		}
+
+		q := randomPrefix(r).Masked()
+		want, wantOK := model[q]
+		if v, ok := tbl.Get(q); v != want || ok != wantOK {
+			t.Fatalf("Get(%v) = %d, %v; want %d, %v", q, v, ok, want, wantOK)
+		}
+
+		addr := randomAddr(r)
+		var best netip.Prefix
+		found := false
+		for p := range model {
+			if p.Contains(addr) && (!found || p.Bits() > best.Bits()) {
+				best, found = p, true
+			}
+		}
+		got, v, ok := tbl.Lookup(addr)
+		if ok != found || got != best || (ok && v != model[best]) {
+			t.Fatalf("Lookup(%v) = %v, %d, %v; want %v, %v", addr, got, v, ok, best, found)
+		}
+
+		var covering, covered []netip.Prefix
+		for p := range model {
+			if p.Addr().Is4() != q.Addr().Is4() {
+				continue
+			}
+			if p.Bits() <= q.Bits() && p.Contains(q.Addr()) {
+				covering = append(covering, p)
+			}
+			if p.Bits() >= q.Bits() && q.Contains(p.Addr()) {
+				covered = append(covered, p)
+			}
+		}
+		slices.SortFunc(covering, func(a, b netip.Prefix) int { return a.Bits() - b.Bits() })
+		slices.SortFunc(covered, byAddr)
+		if got := collect(tbl.Covering(q)); !slices.Equal(got, covering) {
+			t.Fatalf("Covering(%v) = %v, want %v", q, got, covering)
+		}
+		if got := collect(tbl.Covered(q)); !slices.Equal(got, covered) {
+			t.Fatalf("Covered(%v) = %v, want %v", q, got, covered)
+		}
+	}
+
+	all := make([]netip.Prefix, 0, len(model))
+	for p := range model {
+		all = append(all, p)
+	}
+	slices.SortFunc(all, byAddr)
+	if got := collect(tbl.All()); !slices.Equal(got, all) {
+		t.Fatalf("All() = %v, want %v", got, all)
+	}
+	for _, p := range all {
+		tbl.Delete(p)
+	}
+	if tbl.v4.child != [2]*ipnode[int]{} || tbl.v6.child != [2]*ipnode[int]{} {
+		t.Fatal("empty table keeps branches")
+	}
+}
+
+func TestEdgeCases(t *testing.T) {
+	tbl := New[string]()
+	if err := tbl.Insert(netip.Prefix{}, "x"); err != ErrInvalidPrefix {
+		t.Errorf("Insert(invalid) = %v", err)
+	}
+	tbl.Insert(netip.MustParsePrefix("0.0.0.0/0"), "default4")
+	tbl.Insert(netip.MustParsePrefix("10.1.2.3/8"), "ten")
+
+	if p, v, _ := tbl.Lookup(netip.MustParseAddr("::ffff:10.9.9.9")); v != "ten" || p.String() != "10.0.0.0/8" {
+		t.Errorf("mapped lookup = %v, %q", p, v)
+	}
+	if _, _, ok := tbl.Lookup(netip.MustParseAddr("2001:db8::1")); ok {
+		t.Error("an IPv6 address matched an IPv4 route")
+	}
+	if _, _, ok := tbl.Lookup(netip.Addr{}); ok {
+		t.Error("the zero Addr matched")
+	}
+	if v, ok := tbl.Get(netip.MustParsePrefix("10.200.0.0/8")); !ok || v != "ten" {
+		t.Errorf("Get with host bits = %q, %v", v, ok)
+	}
+}
+
+func TestMappedPrefixes(t *testing.T) {
+	tbl := New[string]()
+	mapped := netip.MustParsePrefix("::ffff:10.1.0.0/112")
+	if err := tbl.Insert(mapped, "mapped"); err != nil {
+		t.Fatal(err)
+	}
+	v4 := netip.MustParsePrefix("10.1.0.0/16")
+	for _, a := range []string{"::ffff:10.1.2.3", "10.1.2.3"} {
+		if p, v, ok := tbl.Lookup(netip.MustParseAddr(a)); !ok || v != "mapped" || p != v4 {
+			t.Errorf("Lookup(%s) = %v, %q, %v", a, p, v, ok)
+		}
+	}
+	if v, ok := tbl.Get(v4); !ok || v != "mapped" {
+		t.Errorf("Get(%v) = %q, %v", v4, v, ok)
+	}
+	if got := collect(tbl.All()); !slices.Equal(got, []netip.Prefix{v4}) {
+		t.Errorf("All() = %v", got)
+	}
+
+	tbl.Insert(netip.MustParsePrefix("10.0.0.0/8"), "ten")
+	if got := collect(tbl.Covering(mapped)); len(got) != 2 {
+		t.Errorf("Covering(%v) = %v", mapped, got)
+	}
+	if got := collect(tbl.Covered(netip.MustParsePrefix("::ffff:10.0.0.0/104"))); len(got) != 2 {
+		t.Errorf("Covered(::ffff:10.0.0.0/104) = %v", got)
+	}
+	if !tbl.Delete(mapped) || tbl.Len() != 1 {
+		t.Error("Delete of the mapped prefix failed")
+	}
+
+	// Shorter than /96, a mapped prefix reaches outside the IPv4 space.
+	if err := tbl.Insert(netip.MustParsePrefix("::ffff:0.0.0.0/95"), "x"); err != ErrInvalidPrefix {
+		t.Errorf("Insert(/95) = %v", err)
+	}
+	if _, ok := tbl.Get(netip.MustParsePrefix("::ffff:0.0.0.0/80")); ok {
+		t.Error("Get(/80) found the IPv4 table")
+	}
+}
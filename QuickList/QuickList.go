@@ -0,0 +1,346 @@
+package quicklist
+
+import (
+	"fmt"
+	"iter"
+
+	ziplist "github.com/JustJ3di/Golletions/ZipList"
+)
+
+type qlnode struct {
+	zl         *ziplist.Ziplist
+	prev, next *qlnode
+}
+
+// QuickList is a doubly-linked list of ziplists, as in Redis. Each node keeps
+// a bounded number of entries, so edits only move the bytes of one small
+// ziplist while the list keeps ziplist density.
+type QuickList struct {
+	head, tail *qlnode
+	len        int
+	nodes      int
+
+	maxEntries int
+	maxBytes   int
+	compact    bool
+}
+
+// Option configures a QuickList created with New.
+type Option func(*QuickList)
+
+// WithMaxEntries limits each node to n entries. The default is 128.
+func WithMaxEntries(n int) Option {
+	return func(ql *QuickList) {
+		ql.maxEntries = max(n, 1)
+	}
+}
+
+// WithMaxBytes limits the encoded size of each node to n bytes. The default
+// is 8 KiB. A single entry larger than n still gets a node of its own.
+func WithMaxBytes(n int) Option {
+	return func(ql *QuickList) {
+		ql.maxBytes = n
+	}
+}
+
+// WithCompact stores every node as a compact ziplist (see ziplist.NewCompact).
+func WithCompact() Option {
+	return func(ql *QuickList) {
+		ql.compact = true
+	}
+}
+
+func New(opts ...Option) *QuickList {
+	ql := &QuickList{maxEntries: 128, maxBytes: 8 << 10}
+	for _, opt := range opts {
+		opt(ql)
+	}
+	return ql
+}
+
+func (ql *QuickList) newNode() *qlnode {
+	if ql.compact {
+		return &qlnode{zl: ziplist.NewCompact(64)}
+	}
+	return &qlnode{zl: ziplist.New(64)}
+}
+
+// full reports whether n has reached one of the node limits.
+func (ql *QuickList) full(n *qlnode) bool {
+	return n.zl.Len() >= ql.maxEntries || n.zl.Size() >= ql.maxBytes
+}
+
+// Len returns the number of entries.
+func (ql *QuickList) Len() int {
+	return ql.len
+}
+
+// Nodes returns the number of ziplists the entries are spread over.
+func (ql *QuickList) Nodes() int {
+	return ql.nodes
+}
+
+// linkAfter inserts n after at, or at the head when at is nil.
+func (ql *QuickList) linkAfter(at, n *qlnode) {
+	n.prev = at
+	if at == nil {
+		n.next = ql.head
+		ql.head = n
+	} else {
+		n.next = at.next
+		at.next = n
+	}
+	if n.next != nil {
+		n.next.prev = n
+	} else {
+		ql.tail = n
+	}
+	ql.nodes++
+}
+
+func (ql *QuickList) unlink(n *qlnode) {
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else {
+		ql.head = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else {
+		ql.tail = n.prev
+	}
+	ql.nodes--
+}
+
+// PushBack appends value in O(1).
+func (ql *QuickList) PushBack(value any) error {
+	if ql.tail == nil || ql.full(ql.tail) {
+		n := ql.newNode()
+		if err := n.zl.Push(value); err != nil {
+			return err
+		}
+		ql.linkAfter(ql.tail, n)
+	} else if err := ql.tail.zl.Push(value); err != nil {
+		return err
+	}
+	ql.len++
+	return nil
+}
+
+// PushFront prepends value in O(1).
+func (ql *QuickList) PushFront(value any) error {
+	if ql.head == nil || ql.full(ql.head) {
+		n := ql.newNode()
+		if err := n.zl.Push(value); err != nil {
+			return err
+		}
+		ql.linkAfter(nil, n)
+	} else if err := ql.head.zl.PushFront(value); err != nil {
+		return err
+	}
+	ql.len++
+	return nil
+}
+
+// PopBack removes the last entry and returns its value.
+func (ql *QuickList) PopBack() (any, error) {
+	if ql.len == 0 {
+		return nil, fmt.Errorf("quicklist is empty")
+	}
+	n := ql.tail
+	val, err := n.zl.Pop()
+	if err != nil {
+		return nil, err
+	}
+	ql.removed(n)
+	return val, nil
+}
+
+// PopFront removes the first entry and returns its value.
+func (ql *QuickList) PopFront() (any, error) {
+	if ql.len == 0 {
+		return nil, fmt.Errorf("quicklist is empty")
+	}
+	n := ql.head
+	val, err := n.zl.At(0)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.zl.Remove(0); err != nil {
+		return nil, err
+	}
+	ql.removed(n)
+	return val, nil
+}
+
+// removed updates the list after an entry of n was removed.
+func (ql *QuickList) removed(n *qlnode) {
+	ql.len--
+	if n.zl.Len() == 0 {
+		ql.unlink(n)
+	}
+}
+
+// locate returns the node holding index and the position of the entry in
+// it, walking from the closer end.
+func (ql *QuickList) locate(index int) (*qlnode, int) {
+	if index < ql.len/2 {
+		n := ql.head
+		for index >= n.zl.Len() {
+			index -= n.zl.Len()
+			n = n.next
+		}
+		return n, index
+	}
+	n := ql.tail
+	index = ql.len - 1 - index
+	for index >= n.zl.Len() {
+		index -= n.zl.Len()
+		n = n.prev
+	}
+	return n, n.zl.Len() - 1 - index
+}
+
+// At returns the value at the given index.
+func (ql *QuickList) At(index int) (any, error) {
+	if index < 0 || index >= ql.len {
+		return nil, fmt.Errorf("index out of range")
+	}
+	n, i := ql.locate(index)
+	return n.zl.At(i)
+}
+
+// Set replaces the value at the given index.
+func (ql *QuickList) Set(index int, value any) error {
+	if index < 0 || index >= ql.len {
+		return fmt.Errorf("index out of range")
+	}
+	n, i := ql.locate(index)
+	return n.zl.Set(i, value)
+}
+
+// Insert places value at index, shifting the following entries back.
+// index may be equal to Len(), which appends. A full node is split in two
+// first.
+func (ql *QuickList) Insert(index int, value any) error {
+	if index < 0 || index > ql.len {
+		return fmt.Errorf("index out of range")
+	}
+	if index == ql.len {
+		return ql.PushBack(value)
+	}
+
+	n, i := ql.locate(index)
+	if ql.full(n) && n.zl.Len() == 1 {
+		// Nothing to split: the value gets a node of its own.
+		m := ql.newNode()
+		if err := m.zl.Push(value); err != nil {
+			return err
+		}
+		ql.linkAfter(n.prev, m)
+		ql.len++
+		return nil
+	}
+	if ql.full(n) {
+		right, err := ql.split(n, n.zl.Len()/2)
+		if err != nil {
+			return err
+		}
+		if i >= n.zl.Len() {
+			n, i = right, i-n.zl.Len()
+		}
+	}
+	if err := n.zl.Insert(i, value); err != nil {
+		return err
+	}
+	ql.len++
+	return nil
+}
+
+// split moves the entries of n from position at onwards to a new node after
+// it, and returns the new node.
+func (ql *QuickList) split(n *qlnode, at int) (*qlnode, error) {
+	right := ql.newNode()
+	if err := right.zl.AppendRange(n.zl, at, n.zl.Len()-at); err != nil {
+		return nil, err
+	}
+	if err := n.zl.RemoveRange(at, n.zl.Len()-at); err != nil {
+		return nil, err
+	}
+	ql.linkAfter(n, right)
+	return right, nil
+}
+
+// Remove deletes the entry at index. The node it leaves behind is merged
+// with a neighbour when both fit in one node.
+func (ql *QuickList) Remove(index int) error {
+	if index < 0 || index >= ql.len {
+		return fmt.Errorf("index out of range")
+	}
+	n, i := ql.locate(index)
+	if err := n.zl.Remove(i); err != nil {
+		return err
+	}
+	ql.removed(n)
+	if n.zl.Len() == 0 {
+		return nil
+	}
+
+	if n.prev != nil && ql.fits(n.prev, n) {
+		return ql.merge(n.prev, n)
+	}
+	if n.next != nil && ql.fits(n, n.next) {
+		return ql.merge(n, n.next)
+	}
+	return nil
+}
+
+func (ql *QuickList) fits(a, b *qlnode) bool {
+	return a.zl.Len()+b.zl.Len() <= ql.maxEntries && a.zl.Size()+b.zl.Size() <= ql.maxBytes
+}
+
+// merge appends the entries of b, which follows a, to a and drops b.
+func (ql *QuickList) merge(a, b *qlnode) error {
+	if err := a.zl.AppendRange(b.zl, 0, b.zl.Len()); err != nil {
+		return err
+	}
+	ql.unlink(b)
+	return nil
+}
+
+func (ql *QuickList) Clear() {
+	ql.head, ql.tail = nil, nil
+	ql.len, ql.nodes = 0, 0
+}
+
+// All returns the entries from the first to the last, with their index.
+// The list must not be modified during the loop.
+func (ql *QuickList) All() iter.Seq2[int, ziplist.Entry] {
+	return func(yield func(int, ziplist.Entry) bool) {
+		base := 0
+		for n := ql.head; n != nil; n = n.next {
+			for i, e := range n.zl.All() {
+				if !yield(base+i, e) {
+					return
+				}
+			}
+			base += n.zl.Len()
+		}
+	}
+}
+
+// Backward returns the entries from the last to the first, with their index.
+// The list must not be modified during the loop.
+func (ql *QuickList) Backward() iter.Seq2[int, ziplist.Entry] {
+	return func(yield func(int, ziplist.Entry) bool) {
+		base := ql.len
+		for n := ql.tail; n != nil; n = n.prev {
+			base -= n.zl.Len()
+			for i, e := range n.zl.Backward() {
+				if !yield(base+i, e) {
+					return
+				}
+			}
+		}
+	}
+}
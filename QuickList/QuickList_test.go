@@ -0,0 +1,119 @@
+package quicklist
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	ziplist "github.com/JustJ3di/Golletions/ZipList"
+)
+
+// check fails unless ql holds want and its nodes are linked both ways, none
+// of them empty or over the entry limit.
+func check(t *testing.T, ql *QuickList, want []any) {
+	t.Helper()
+	if ql.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", ql.Len(), len(want))
+	}
+	nodes, total := 0, 0
+	var prev *qlnode
+	for n := ql.head; n != nil; n = n.next {
+		if n.prev != prev {
+			t.Fatalf("node %d: bad prev link", nodes)
+		}
+		if n.zl.Len() == 0 || n.zl.Len() > ql.maxEntries {
+			t.Fatalf("node %d holds %d entries", nodes, n.zl.Len())
+		}
+		prev = n
+		nodes++
+		total += n.zl.Len()
+	}
+	if prev != ql.tail || nodes != ql.Nodes() || total != ql.Len() {
+		t.Fatalf("tail or counts out of sync: %d nodes, %d entries", nodes, total)
+	}
+
+	for i, e := range ql.All() {
+		if got := e.Value(); !reflect.DeepEqual(got, want[i]) {
+			t.Fatalf("All: entry %d = %#v, want %#v", i, got, want[i])
+		}
+		if ql.compact && isInt(e) && e.Type() < ziplist.TYPE_CINT8 {
+			t.Fatalf("entry %d lost its compact encoding: type %#x", i, e.Type())
+		}
+	}
+	for i, e := range ql.Backward() {
+		if got := e.Value(); !reflect.DeepEqual(got, want[i]) {
+			t.Fatalf("Backward: entry %d = %#v, want %#v", i, got, want[i])
+		}
+	}
+}
+
+func isInt(e ziplist.Entry) bool {
+	_, ok := e.Int()
+	return ok
+}
+
+func TestAgainstModel(t *testing.T) {
+	big := strings.Repeat("x", 300)
+	configs := map[string][]Option{
+		"default":   nil,
+		"1 entry":   {WithMaxEntries(1)},
+		"4 entries": {WithMaxEntries(4)},
+		"compact":   {WithMaxEntries(4), WithCompact()},
+		"64 bytes":  {WithMaxBytes(64), WithCompact()},
+	}
+	for name, opts := range configs {
+		t.Run(name, func(t *testing.T) {
+			r := rand.New(rand.NewSource(1))
+			ql := New(opts...)
+			var model []any
+			value := func() any {
+				switch r.Intn(4) {
+				case 0:
+					return big
+				case 1:
+					return "s"
+				}
+				return int64(r.Intn(1 << 20))
+			}
+
+			for range 3000 {
+				switch op := r.Intn(8); {
+				case op == 0 || len(model) == 0:
+					v := value()
+					ql.PushBack(v)
+					model = append(model, v)
+				case op == 1:
+					v := value()
+					ql.PushFront(v)
+					model = append([]any{v}, model...)
+				case op <= 3:
+					i, v := r.Intn(len(model)+1), value()
+					if err := ql.Insert(i, v); err != nil {
+						t.Fatal(err)
+					}
+					model = append(model[:i], append([]any{v}, model[i:]...)...)
+				case op <= 5:
+					i := r.Intn(len(model))
+					if err := ql.Remove(i); err != nil {
+						t.Fatal(err)
+					}
+					model = append(model[:i], model[i+1:]...)
+				case op == 6:
+					i, v := r.Intn(len(model)), value()
+					if err := ql.Set(i, v); err != nil {
+						t.Fatal(err)
+					}
+					model[i] = v
+				default:
+					got, err := ql.PopBack()
+					if err != nil || !reflect.DeepEqual(got, model[len(model)-1]) {
+						t.Fatalf("PopBack() = %v, %v; want %v", got, err, model[len(model)-1])
+					}
+					model = model[:len(model)-1]
+				}
+				check(t, ql, model)
+			}
+		})
+	}
+}
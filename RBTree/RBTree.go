@@ -2,6 +2,7 @@ package rbtree
 
 import (
 	"fmt"
+	"iter"
 )
 
 type Key interface {
@@ -206,6 +207,20 @@ func (rb *RBtree[T]) Max() any {
 	return curr.value
 }
 
+// All returns the key-value pairs in key order.
+func (rb *RBtree[T]) All() iter.Seq2[T, any] {
+	return func(yield func(T, any) bool) {
+		var walk func(n *rbnode[T]) bool
+		walk = func(n *rbnode[T]) bool {
+			if n == nil {
+				return true
+			}
+			return walk(n.left) && yield(n.key, n.value) && walk(n.right)
+		}
+		walk(rb.root)
+	}
+}
+
 func (rb *RBtree[T]) PrintInOrder() {
 	rb.orderedPrintRecursive(rb.root)
 }
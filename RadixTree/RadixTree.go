@@ -0,0 +1,176 @@
+package radix
+
+import (
+	"slices"
+	"strings"
+)
+
+// radixnode is reached through an edge labelled with label. Children are kept
+// sorted by the first byte of their label; no two children share it.
+type radixnode struct {
+	label    string
+	children []*radixnode
+	end      bool
+}
+
+// Tree is a compressed (Patricia) trie. Chains of single-child nodes are
+// collapsed into one edge, so long keys with shared prefixes cost a handful of
+// nodes instead of one node per rune.
+type Tree struct {
+	root *radixnode
+}
+
+func New() *Tree {
+	return &Tree{root: &radixnode{}}
+}
+
+func (n *radixnode) child(b byte) (int, bool) {
+	return slices.BinarySearchFunc(n.children, b, func(c *radixnode, b byte) int {
+		return int(c.label[0]) - int(b)
+	})
+}
+
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+func (t *Tree) Insert(str string) {
+	curr := t.root
+	for {
+		if str == "" {
+			curr.end = true
+			return
+		}
+
+		i, found := curr.child(str[0])
+		if !found {
+			curr.children = slices.Insert(curr.children, i, &radixnode{label: str, end: true})
+			return
+		}
+
+		next := curr.children[i]
+		common := commonPrefix(next.label, str)
+		if common < len(next.label) {
+			// Split the edge: the shared part becomes a new inner node and the
+			// old child hangs below it with the rest of its label.
+			mid := &radixnode{label: next.label[:common], children: []*radixnode{next}}
+			next.label = next.label[common:]
+			curr.children[i] = mid
+			next = mid
+		}
+		str = str[common:]
+		curr = next
+	}
+}
+
+func (t *Tree) Search(key string) bool {
+	curr := t.root
+	for key != "" {
+		i, found := curr.child(key[0])
+		if !found || !strings.HasPrefix(key, curr.children[i].label) {
+			return false
+		}
+		curr = curr.children[i]
+		key = key[len(curr.label):]
+	}
+	return curr.end
+}
+
+// StartsWith reports whether some stored word begins with prefix.
+func (t *Tree) StartsWith(prefix string) bool {
+	_, _, ok := t.locate(prefix)
+	return ok
+}
+
+// locate finds the node under which every word starting with prefix lives.
+// The prefix may end in the middle of that node's edge; rest is the part of
+// the edge label past the end of prefix.
+func (t *Tree) locate(prefix string) (n *radixnode, rest string, ok bool) {
+	curr := t.root
+	for prefix != "" {
+		i, found := curr.child(prefix[0])
+		if !found {
+			return nil, "", false
+		}
+		next := curr.children[i]
+		if strings.HasPrefix(next.label, prefix) {
+			return next, next.label[len(prefix):], true
+		}
+		if !strings.HasPrefix(prefix, next.label) {
+			return nil, "", false
+		}
+		prefix = prefix[len(next.label):]
+		curr = next
+	}
+	return curr, "", true
+}
+
+// Delete removes key and merges the edges left with a single child.
+// It returns false if key was not stored.
+func (t *Tree) Delete(key string) bool {
+	var parent *radixnode
+	curr := t.root
+	for key != "" {
+		i, found := curr.child(key[0])
+		if !found || !strings.HasPrefix(key, curr.children[i].label) {
+			return false
+		}
+		parent = curr
+		curr = curr.children[i]
+		key = key[len(curr.label):]
+	}
+	if !curr.end {
+		return false
+	}
+	curr.end = false
+
+	if curr == t.root {
+		return true
+	}
+	switch len(curr.children) {
+	case 0:
+		i, _ := parent.child(curr.label[0])
+		parent.children = slices.Delete(parent.children, i, i+1)
+		// The parent may now be a pass-through node with a single child.
+		if parent != t.root && !parent.end && len(parent.children) == 1 {
+			parent.merge()
+		}
+	case 1:
+		curr.merge()
+	}
+	return true
+}
+
+// merge folds the only child of n into n.
+func (n *radixnode) merge() {
+	child := n.children[0]
+	n.label += child.label
+	n.children = child.children
+	n.end = child.end
+}
+
+// KeysWithPrefix returns every stored word starting with prefix, sorted.
+func (t *Tree) KeysWithPrefix(prefix string) []string {
+	n, rest, ok := t.locate(prefix)
+	if !ok {
+		return nil
+	}
+
+	var words []string
+	var collect func(n *radixnode, path string)
+	collect = func(n *radixnode, path string) {
+		if n.end {
+			words = append(words, path)
+		}
+		// Children are sorted by their first byte, so the output is sorted too.
+		for _, child := range n.children {
+			collect(child, path+child.label)
+		}
+	}
+	collect(n, prefix+rest)
+	return words
+}
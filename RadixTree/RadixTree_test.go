@@ -0,0 +1,165 @@
+package radix
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	trie "github.com/JustJ3di/Golletions/Trie"
+)
+
+// urlKeys returns n distinct URL-like keys with long shared prefixes.
+func urlKeys(n int) []string {
+	r := rand.New(rand.NewSource(1))
+	services := []string{"users", "orders", "payments", "inventory", "search"}
+	seen := map[string]bool{}
+	var keys []string
+	for len(keys) < n {
+		k := fmt.Sprintf("https://api.example.com/v%d/%s/%d/items/%d",
+			1+r.Intn(3), services[r.Intn(len(services))], r.Intn(1000), r.Intn(100))
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+func TestAgainstMap(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	words := []string{"", "a", "ab", "abc", "abd", "b", "ba", "bab", "/api", "/api/v1", "/api/v2", "/apix"}
+	for round := 0; round < 200; round++ {
+		tr := New()
+		ref := map[string]bool{}
+		for step := 0; step < 50; step++ {
+			w := words[r.Intn(len(words))]
+			if r.Intn(3) == 0 {
+				if got := tr.Delete(w); got != ref[w] {
+					t.Fatalf("Delete(%q) = %v, want %v", w, got, ref[w])
+				}
+				delete(ref, w)
+			} else {
+				tr.Insert(w)
+				ref[w] = true
+			}
+
+			for _, p := range words {
+				if got := tr.Search(p); got != ref[p] {
+					t.Fatalf("Search(%q) = %v, want %v", p, got, ref[p])
+				}
+				var want []string
+				for w := range ref {
+					if strings.HasPrefix(w, p) {
+						want = append(want, w)
+					}
+				}
+				slices.Sort(want)
+				if got := tr.KeysWithPrefix(p); !slices.Equal(got, want) {
+					t.Fatalf("KeysWithPrefix(%q) = %q, want %q", p, got, want)
+				}
+				if got := tr.StartsWith(p); got != (len(want) > 0 || p == "") {
+					t.Fatalf("StartsWith(%q) = %v", p, got)
+				}
+			}
+		}
+	}
+}
+
+// set is the API shared by radix.Tree and trie.Trie.
+type set interface {
+	Insert(string)
+	Search(string) bool
+	KeysWithPrefix(string) []string
+}
+
+var impls = []struct {
+	name string
+	new  func() set
+}{
+	{"radix", func() set { return New() }},
+	{"trie", func() set { return trie.New() }},
+}
+
+const benchKeys = 10000
+
+func fill(s set, keys []string) set {
+	for _, k := range keys {
+		s.Insert(k)
+	}
+	return s
+}
+
+func BenchmarkInsert(b *testing.B) {
+	keys := urlKeys(benchKeys)
+	for _, impl := range impls {
+		b.Run(impl.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for range b.N {
+				fill(impl.new(), keys)
+			}
+		})
+	}
+}
+
+func BenchmarkSearch(b *testing.B) {
+	keys := urlKeys(benchKeys)
+	for _, impl := range impls {
+		b.Run(impl.name, func(b *testing.B) {
+			s := fill(impl.new(), keys)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := range b.N {
+				s.Search(keys[i%len(keys)])
+			}
+		})
+	}
+}
+
+func BenchmarkKeysWithPrefix(b *testing.B) {
+	keys := urlKeys(benchKeys)
+	prefixes := []string{
+		"https://api.example.com/v1/users/1",
+		"https://api.example.com/v2/orders/",
+		"https://api.example.com/v3/search/99/items/",
+	}
+	for _, impl := range impls {
+		b.Run(impl.name, func(b *testing.B) {
+			s := fill(impl.new(), keys)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := range b.N {
+				s.KeysWithPrefix(prefixes[i%len(prefixes)])
+			}
+		})
+	}
+}
+
+// BenchmarkHeap reports the live heap held by each structure after
+// inserting the keys, as heap-bytes/key.
+func BenchmarkHeap(b *testing.B) {
+	keys := urlKeys(benchKeys)
+	for _, impl := range impls {
+		b.Run(impl.name, func(b *testing.B) {
+			var total uint64
+			for range b.N {
+				total += liveHeap(func() any { return fill(impl.new(), keys) })
+			}
+			b.ReportMetric(float64(total)/float64(b.N)/benchKeys, "heap-bytes/key")
+		})
+	}
+}
+
+// liveHeap returns how many heap bytes the value built by build keeps alive.
+func liveHeap(build func() any) uint64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	v := build()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(v)
+	return after.HeapAlloc - before.HeapAlloc
+}
@@ -0,0 +1,222 @@
+package suffixarray
+
+import (
+	"bytes"
+	"slices"
+	"sort"
+)
+
+// Index is a suffix array over a byte string, with the LCP array alongside.
+// Suffixes are sorted once, so any substring is found by binary search.
+type Index struct {
+	data []byte
+	sa   []int
+	lcp  []int
+}
+
+// New builds the index for data in O(n log n), by prefix doubling with radix
+// sort, and computes the LCP array in O(n) with Kasai's algorithm. data must
+// not be modified afterwards.
+func New(data []byte) *Index {
+	x := &Index{data: data, sa: buildSA(data)}
+	x.lcp = buildLCP(data, x.sa)
+	return x
+}
+
+func buildSA(s []byte) []int {
+	n := len(s)
+	sa := make([]int, n)
+	if n == 0 {
+		return sa
+	}
+
+	// Round 0: sort by the first byte alone.
+	rank := make([]int, n)
+	cnt := make([]int, max(256, n)+1)
+	for _, c := range s {
+		cnt[int(c)+1]++
+	}
+	for i := 1; i <= 256; i++ {
+		cnt[i] += cnt[i-1]
+	}
+	for i, c := range s {
+		sa[cnt[c]] = i
+		cnt[c]++
+	}
+	classes := 1
+	for i := 1; i < n; i++ {
+		if s[sa[i]] != s[sa[i-1]] {
+			classes++
+		}
+		rank[sa[i]] = classes - 1
+	}
+
+	// Round k: suffixes are sorted by their first k bytes and rank holds the
+	// class of each one. Sorting by the pair (rank[i], rank[i+k]) gives the
+	// order by the first 2k bytes.
+	second := make([]int, n)
+	next := make([]int, n)
+	for k := 1; classes < n; k <<= 1 {
+		// Order by the second key: suffixes shorter than k sort first, the
+		// others follow in the order of the suffix k bytes further on.
+		p := 0
+		for i := n - k; i < n; i++ {
+			second[p] = i
+			p++
+		}
+		for _, j := range sa {
+			if j >= k {
+				second[p] = j - k
+				p++
+			}
+		}
+
+		// Stable counting sort by the first key.
+		clear(cnt[:classes+1])
+		for _, r := range rank {
+			cnt[r+1]++
+		}
+		for i := 1; i <= classes; i++ {
+			cnt[i] += cnt[i-1]
+		}
+		for _, i := range second {
+			sa[cnt[rank[i]]] = i
+			cnt[rank[i]]++
+		}
+
+		key2 := func(i int) int {
+			if i+k < n {
+				return rank[i+k]
+			}
+			return -1
+		}
+		next[sa[0]] = 0
+		classes = 1
+		for i := 1; i < n; i++ {
+			a, b := sa[i-1], sa[i]
+			if rank[a] != rank[b] || key2(a) != key2(b) {
+				classes++
+			}
+			next[b] = classes - 1
+		}
+		rank, next = next, rank
+	}
+	return sa
+}
+
+// buildLCP returns lcp, where lcp[i] is the length of the longest common
+// prefix of the suffixes at sa[i-1] and sa[i], and lcp[0] is 0.
+func buildLCP(s []byte, sa []int) []int {
+	n := len(s)
+	lcp := make([]int, n)
+	rank := make([]int, n)
+	for i, p := range sa {
+		rank[p] = i
+	}
+
+	h := 0
+	for p := 0; p < n; p++ {
+		if rank[p] == 0 {
+			h = 0
+			continue
+		}
+		q := sa[rank[p]-1]
+		for p+h < n && q+h < n && s[p+h] == s[q+h] {
+			h++
+		}
+		lcp[rank[p]] = h
+		if h > 0 {
+			h--
+		}
+	}
+	return lcp
+}
+
+// lookup returns the range of sa holding the suffixes that start with pattern.
+func (x *Index) lookup(pattern []byte) (lo, hi int) {
+	prefix := func(i int) []byte {
+		p := x.sa[i]
+		return x.data[p:min(p+len(pattern), len(x.data))]
+	}
+	lo = sort.Search(len(x.sa), func(i int) bool {
+		return bytes.Compare(prefix(i), pattern) >= 0
+	})
+	hi = lo + sort.Search(len(x.sa)-lo, func(i int) bool {
+		return !bytes.Equal(prefix(lo+i), pattern)
+	})
+	return lo, hi
+}
+
+// Contains reports whether pattern occurs in the data. The empty pattern
+// always does.
+func (x *Index) Contains(pattern []byte) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	lo, hi := x.lookup(pattern)
+	return lo < hi
+}
+
+// Count returns the number of occurrences of pattern, overlapping ones
+// included. Like bytes.Count, it finds the empty pattern at every offset
+// from 0 to the length of the data, so n+1 times.
+func (x *Index) Count(pattern []byte) int {
+	if len(pattern) == 0 {
+		return len(x.data) + 1
+	}
+	lo, hi := x.lookup(pattern)
+	return hi - lo
+}
+
+// FindAll returns the offsets of every occurrence of pattern, in increasing
+// order. The empty pattern occurs at every offset, the length of the data
+// included.
+func (x *Index) FindAll(pattern []byte) []int {
+	if len(pattern) == 0 {
+		pos := make([]int, len(x.data)+1)
+		for i := range pos {
+			pos[i] = i
+		}
+		return pos
+	}
+	lo, hi := x.lookup(pattern)
+	if lo == hi {
+		return nil
+	}
+	pos := slices.Clone(x.sa[lo:hi])
+	slices.Sort(pos)
+	return pos
+}
+
+// LongestRepeated returns the longest substring occurring at least twice,
+// and the offsets of two of its occurrences, which may overlap. It returns
+// nil, -1, -1 if no byte repeats.
+func (x *Index) LongestRepeated() (sub []byte, first, second int) {
+	best := 0
+	for i := 1; i < len(x.lcp); i++ {
+		if x.lcp[i] > x.lcp[best] {
+			best = i
+		}
+	}
+	if len(x.lcp) == 0 || x.lcp[best] == 0 {
+		return nil, -1, -1
+	}
+	first, second = x.sa[best-1], x.sa[best]
+	if first > second {
+		first, second = second, first
+	}
+	return x.data[first : first+x.lcp[best]], first, second
+}
+
+// SA returns the suffix array: the offsets of the suffixes of the data in
+// lexicographic order. The slice must not be modified.
+func (x *Index) SA() []int {
+	return x.sa
+}
+
+// LCP returns the LCP array: LCP()[i] is the length of the longest common
+// prefix of the suffixes at SA()[i-1] and SA()[i]. The slice must not be
+// modified.
+func (x *Index) LCP() []int {
+	return x.lcp
+}
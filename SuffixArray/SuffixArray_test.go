@@ -0,0 +1,111 @@
+package suffixarray
+
+import (
+	"bytes"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+func naiveFindAll(data, pattern []byte) []int {
+	var pos []int
+	for i := 0; i+len(pattern) <= len(data); i++ {
+		if bytes.Equal(data[i:i+len(pattern)], pattern) {
+			pos = append(pos, i)
+		}
+	}
+	return pos
+}
+
+func naiveLongestRepeated(data []byte) int {
+	best := 0
+	for i := range data {
+		for j := i + 1; j < len(data); j++ {
+			k := 0
+			for j+k < len(data) && data[i+k] == data[j+k] {
+				k++
+			}
+			best = max(best, k)
+		}
+	}
+	return best
+}
+
+func randomData(r *rand.Rand, n int, alphabet []byte) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return b
+}
+
+func TestHighBytes(t *testing.T) {
+	data := []byte{0xff, 'a', 0xff, 'b'}
+	x := New(data)
+	if got := x.Count([]byte{0xff}); got != 2 {
+		t.Fatalf("Count(0xff) = %d, want 2", got)
+	}
+	if got := x.FindAll([]byte{0xff}); !slices.Equal(got, []int{0, 2}) {
+		t.Fatalf("FindAll(0xff) = %v, want [0 2]", got)
+	}
+}
+
+func TestAgainstBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	alphabets := [][]byte{
+		[]byte("ab"),
+		[]byte("acgt"),
+		{0x00, 0x01, 0x7f, 0x80, 0xfe, 0xff},
+	}
+	for round := 0; round < 300; round++ {
+		data := randomData(r, r.Intn(200), alphabets[round%len(alphabets)])
+		x := New(data)
+
+		for i := 1; i < len(x.SA()); i++ {
+			a, b := data[x.SA()[i-1]:], data[x.SA()[i]:]
+			if bytes.Compare(a, b) >= 0 {
+				t.Fatalf("%x: suffixes %d and %d out of order", data, i-1, i)
+			}
+			lcp := 0
+			for lcp < len(a) && lcp < len(b) && a[lcp] == b[lcp] {
+				lcp++
+			}
+			if x.LCP()[i] != lcp {
+				t.Fatalf("%x: LCP[%d] = %d, want %d", data, i, x.LCP()[i], lcp)
+			}
+		}
+
+		for q := 0; q < 20; q++ {
+			var pattern []byte
+			if q == 1 {
+				// The empty pattern, found at every offset up to len(data).
+				pattern = []byte{}
+			} else if len(data) > 0 && q%2 == 0 {
+				i := r.Intn(len(data))
+				pattern = data[i:min(len(data), i+1+r.Intn(5))]
+			} else {
+				pattern = randomData(r, 1+r.Intn(4), alphabets[round%len(alphabets)])
+			}
+			want := naiveFindAll(data, pattern)
+			if got := x.FindAll(pattern); !slices.Equal(got, want) {
+				t.Fatalf("%x: FindAll(%x) = %v, want %v", data, pattern, got, want)
+			}
+			if got := x.Count(pattern); got != len(want) {
+				t.Fatalf("%x: Count(%x) = %d, want %d", data, pattern, got, len(want))
+			}
+			if got := x.Contains(pattern); got != (len(want) > 0) {
+				t.Fatalf("%x: Contains(%x) = %v", data, pattern, got)
+			}
+		}
+
+		sub, first, second := x.LongestRepeated()
+		if want := naiveLongestRepeated(data); len(sub) != want {
+			t.Fatalf("%x: LongestRepeated length %d, want %d", data, len(sub), want)
+		}
+		if sub != nil {
+			if first >= second || !bytes.Equal(data[first:first+len(sub)], sub) || !bytes.Equal(data[second:second+len(sub)], sub) {
+				t.Fatalf("%x: LongestRepeated = %x at %d, %d", data, sub, first, second)
+			}
+		}
+	}
+}
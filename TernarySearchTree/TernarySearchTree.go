@@ -0,0 +1,239 @@
+package tst
+
+type tstnode struct {
+	ch         rune
+	lo, eq, hi *tstnode
+	end        bool
+}
+
+// Tree is a ternary search tree. Each node holds one rune and three children:
+// runes smaller and larger than it at the same position (lo, hi), and the
+// next position of the words that continue with it (eq). Nodes cost the same
+// whatever the alphabet size, and an in-order walk yields words sorted.
+type Tree struct {
+	root  *tstnode
+	empty bool // the empty word has no node of its own
+	len   int
+}
+
+func New() *Tree {
+	return &Tree{}
+}
+
+func (t *Tree) Insert(str string) {
+	runes := []rune(str)
+	if len(runes) == 0 {
+		if !t.empty {
+			t.empty = true
+			t.len++
+		}
+		return
+	}
+
+	link := &t.root
+	for i := 0; ; {
+		n := *link
+		if n == nil {
+			n = &tstnode{ch: runes[i]}
+			*link = n
+		}
+		switch {
+		case runes[i] < n.ch:
+			link = &n.lo
+		case runes[i] > n.ch:
+			link = &n.hi
+		case i < len(runes)-1:
+			link = &n.eq
+			i++
+		default:
+			if !n.end {
+				n.end = true
+				t.len++
+			}
+			return
+		}
+	}
+}
+
+// find returns the node holding the last rune of key.
+func (t *Tree) find(runes []rune) *tstnode {
+	n := t.root
+	for i := 0; n != nil; {
+		switch {
+		case runes[i] < n.ch:
+			n = n.lo
+		case runes[i] > n.ch:
+			n = n.hi
+		case i < len(runes)-1:
+			n = n.eq
+			i++
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+func (t *Tree) Search(key string) bool {
+	if key == "" {
+		return t.empty
+	}
+	n := t.find([]rune(key))
+	return n != nil && n.end
+}
+
+// StartsWith reports whether some stored word begins with prefix.
+func (t *Tree) StartsWith(prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	// Delete can leave a node that only splits its lo and hi subtrees.
+	n := t.find([]rune(prefix))
+	return n != nil && (n.end || n.eq != nil)
+}
+
+// Delete removes key and prunes the nodes left without words.
+// It returns false if key was not stored.
+func (t *Tree) Delete(key string) bool {
+	if key == "" {
+		if !t.empty {
+			return false
+		}
+		t.empty = false
+		t.len--
+		return true
+	}
+
+	var ok bool
+	t.root, ok = del(t.root, []rune(key), 0)
+	if ok {
+		t.len--
+	}
+	return ok
+}
+
+func del(n *tstnode, runes []rune, i int) (*tstnode, bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	var ok bool
+	switch {
+	case runes[i] < n.ch:
+		n.lo, ok = del(n.lo, runes, i)
+	case runes[i] > n.ch:
+		n.hi, ok = del(n.hi, runes, i)
+	case i < len(runes)-1:
+		n.eq, ok = del(n.eq, runes, i+1)
+	default:
+		ok = n.end
+		n.end = false
+	}
+
+	// A node that ends no word and leads nowhere only matters as a split
+	// point between its lo and hi subtrees. With one of them missing, the
+	// other can take its place.
+	if ok && !n.end && n.eq == nil {
+		if n.lo == nil {
+			return n.hi, true
+		}
+		if n.hi == nil {
+			return n.lo, true
+		}
+	}
+	return n, ok
+}
+
+// KeysWithPrefix returns every stored word starting with prefix, sorted.
+func (t *Tree) KeysWithPrefix(prefix string) []string {
+	var words []string
+	if prefix == "" {
+		if t.empty {
+			words = append(words, "")
+		}
+		collect(t.root, nil, &words)
+		return words
+	}
+
+	runes := []rune(prefix)
+	n := t.find(runes)
+	if n == nil {
+		return nil
+	}
+	if n.end {
+		words = append(words, prefix)
+	}
+	collect(n.eq, runes, &words)
+	return words
+}
+
+// collect appends, in order, the words of the subtree rooted at n, which all
+// start with path.
+func collect(n *tstnode, path []rune, words *[]string) {
+	if n == nil {
+		return
+	}
+	collect(n.lo, path, words)
+	path = append(path, n.ch)
+	if n.end {
+		*words = append(*words, string(path))
+	}
+	collect(n.eq, path, words)
+	collect(n.hi, path[:len(path)-1], words)
+}
+
+// NearNeighbors returns the stored words with as many runes as word that
+// differ from it in at most maxDist positions (Hamming distance), sorted.
+func (t *Tree) NearNeighbors(word string, maxDist int) []string {
+	var words []string
+	runes := []rune(word)
+	if maxDist < 0 {
+		return nil
+	}
+	if len(runes) == 0 {
+		if t.empty {
+			words = append(words, "")
+		}
+		return words
+	}
+	near(t.root, runes, 0, maxDist, nil, &words)
+	return words
+}
+
+func near(n *tstnode, runes []rune, i, dist int, path []rune, words *[]string) {
+	if n == nil {
+		return
+	}
+	ch := runes[i]
+
+	// Smaller and larger runes are only worth a look if they match ch or if
+	// there is a mismatch left to spend on them.
+	if dist > 0 || ch < n.ch {
+		near(n.lo, runes, i, dist, path, words)
+	}
+
+	left := dist
+	if n.ch != ch {
+		left--
+	}
+	if left >= 0 {
+		path = append(path, n.ch)
+		if i == len(runes)-1 {
+			if n.end {
+				*words = append(*words, string(path))
+			}
+		} else {
+			near(n.eq, runes, i+1, left, path, words)
+		}
+		path = path[:len(path)-1]
+	}
+
+	if dist > 0 || ch > n.ch {
+		near(n.hi, runes, i, dist, path, words)
+	}
+}
+
+// Len returns the number of stored words.
+func (t *Tree) Len() int {
+	return t.len
+}
@@ -0,0 +1,89 @@
+package tst
+
+import (
+	"maps"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func randomWord(r *rand.Rand, runes []rune) string {
+	w := make([]rune, r.Intn(6))
+	for i := range w {
+		w[i] = runes[r.Intn(len(runes))]
+	}
+	return string(w)
+}
+
+func hamming(a, b []rune) int {
+	d := 0
+	for i := range a {
+		if a[i] != b[i] {
+			d++
+		}
+	}
+	return d
+}
+
+func TestAgainstMap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, alphabet := range []string{"abc", "中文字符"} {
+		runes := []rune(alphabet)
+		tree := New()
+		model := map[string]bool{}
+
+		for range 3000 {
+			w := randomWord(r, runes)
+			if r.Intn(3) == 0 {
+				if got := tree.Delete(w); got != model[w] {
+					t.Fatalf("Delete(%q) = %v, want %v", w, got, model[w])
+				}
+				delete(model, w)
+			} else {
+				tree.Insert(w)
+				model[w] = true
+			}
+			if tree.Len() != len(model) {
+				t.Fatalf("Len() = %d, want %d", tree.Len(), len(model))
+			}
+
+			q := randomWord(r, runes)
+			if tree.Search(q) != model[q] {
+				t.Fatalf("Search(%q) = %v", q, !model[q])
+			}
+			var prefixed []string
+			for w := range model {
+				if strings.HasPrefix(w, q) {
+					prefixed = append(prefixed, w)
+				}
+			}
+			slices.Sort(prefixed)
+			if got := tree.KeysWithPrefix(q); !slices.Equal(got, prefixed) {
+				t.Fatalf("KeysWithPrefix(%q) = %q, want %q", q, got, prefixed)
+			}
+			if tree.StartsWith(q) != (len(prefixed) > 0) {
+				t.Fatalf("StartsWith(%q) = %v", q, len(prefixed) == 0)
+			}
+
+			maxDist := r.Intn(3)
+			var near []string
+			for w := range model {
+				if a, b := []rune(w), []rune(q); len(a) == len(b) && hamming(a, b) <= maxDist {
+					near = append(near, w)
+				}
+			}
+			slices.Sort(near)
+			if got := tree.NearNeighbors(q, maxDist); !slices.Equal(got, near) {
+				t.Fatalf("NearNeighbors(%q, %d) = %q, want %q", q, maxDist, got, near)
+			}
+		}
+
+		for _, w := range slices.Collect(maps.Keys(model)) {
+			tree.Delete(w)
+		}
+		if tree.root != nil || tree.Len() != 0 {
+			t.Fatal("deleting every word leaves nodes behind")
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package trie
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// cnode is a node of a ConcurrentTrie. Once published, a node is never
+// modified: writers copy the nodes along the path they change.
+type cnode struct {
+	children map[rune]*cnode
+	pass     int
+	count    int
+	word     string
+}
+
+func (n *cnode) clone() *cnode {
+	c := *n
+	c.children = maps.Clone(n.children)
+	if c.children == nil {
+		c.children = make(map[rune]*cnode)
+	}
+	return &c
+}
+
+// ConcurrentTrie is a Trie safe for concurrent use, built for read-mostly
+// workloads. Lookups never block: they load the current root atomically and
+// walk an immutable snapshot. Insert and Delete copy the path they modify and
+// swap the root in one atomic store, so readers see either the old or the new
+// trie, never a mix. Writers are serialized by a mutex.
+type ConcurrentTrie struct {
+	root atomic.Pointer[cnode]
+	mu   sync.Mutex
+	keyOptions
+}
+
+// NewConcurrent returns an empty ConcurrentTrie. It accepts the same options
+// as New.
+func NewConcurrent(opts ...Option) *ConcurrentTrie {
+	cfg := &Trie{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	c := &ConcurrentTrie{keyOptions: cfg.keyOptions}
+	c.root.Store(&cnode{children: make(map[rune]*cnode)})
+	return c
+}
+
+// Insert adds str to the trie. Inserting the same word again increases its
+// count.
+func (c *ConcurrentTrie) Insert(str string) {
+	key := c.key(str)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	root := c.root.Load().clone()
+	root.pass++
+	curr := root
+	for _, ch := range key {
+		var next *cnode
+		if old, exist := curr.children[ch]; exist {
+			next = old.clone()
+		} else {
+			next = &cnode{children: make(map[rune]*cnode)}
+		}
+		next.pass++
+		curr.children[ch] = next
+		curr = next
+	}
+	curr.count++
+	if c.transforms() && curr.word == "" {
+		curr.word = str
+	}
+	c.root.Store(root)
+}
+
+// Delete removes one occurrence of key. It returns false if key was not
+// stored.
+func (c *ConcurrentTrie) Delete(key string) bool {
+	key = c.key(key)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	old := c.root.Load()
+	if n := find(old, key); n == nil || n.count == 0 {
+		return false
+	}
+
+	root := old.clone()
+	root.pass--
+	curr := root
+	for _, ch := range key {
+		child := curr.children[ch]
+		if child.pass == 1 {
+			// Nothing below here is stored any more.
+			delete(curr.children, ch)
+			c.root.Store(root)
+			return true
+		}
+		next := child.clone()
+		next.pass--
+		curr.children[ch] = next
+		curr = next
+	}
+	curr.count--
+	if curr.count == 0 {
+		curr.word = ""
+	}
+	c.root.Store(root)
+	return true
+}
+
+func find(n *cnode, key string) *cnode {
+	for _, ch := range key {
+		next, exist := n.children[ch]
+		if !exist {
+			return nil
+		}
+		n = next
+	}
+	return n
+}
+
+func (c *ConcurrentTrie) Search(key string) bool {
+	n := find(c.root.Load(), c.key(key))
+	return n != nil && n.count > 0
+}
+
+// StartsWith reports whether some stored word begins with prefix.
+func (c *ConcurrentTrie) StartsWith(prefix string) bool {
+	return find(c.root.Load(), c.key(prefix)) != nil
+}
+
+// KeysWithPrefix returns every stored word starting with prefix, sorted.
+// A word inserted several times is returned once.
+func (c *ConcurrentTrie) KeysWithPrefix(prefix string) []string {
+	prefix = c.key(prefix)
+	curr := find(c.root.Load(), prefix)
+	if curr == nil {
+		return nil
+	}
+
+	var words []string
+	var collect func(n *cnode, path []rune)
+	collect = func(n *cnode, path []rune) {
+		if n.count > 0 {
+			if n.word != "" {
+				words = append(words, n.word)
+			} else {
+				words = append(words, string(path))
+			}
+		}
+		for ch, child := range n.children {
+			collect(child, append(path, ch))
+		}
+	}
+	collect(curr, []rune(prefix))
+	slices.Sort(words)
+	return words
+}
+
+// CountPrefix returns how many stored words start with prefix, counting
+// duplicates.
+func (c *ConcurrentTrie) CountPrefix(prefix string) int {
+	if n := find(c.root.Load(), c.key(prefix)); n != nil {
+		return n.pass
+	}
+	return 0
+}
+
+// CountWord returns how many times word was inserted and not yet deleted.
+func (c *ConcurrentTrie) CountWord(word string) int {
+	if n := find(c.root.Load(), c.key(word)); n != nil {
+		return n.count
+	}
+	return 0
+}
+
+// Len returns the number of stored words, counting duplicates.
+func (c *ConcurrentTrie) Len() int {
+	return c.root.Load().pass
+}
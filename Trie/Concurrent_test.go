@@ -0,0 +1,121 @@
+package trie
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// dump renders the snapshot rooted at n, counts included.
+func dump(n *cnode, path string, out *[]string) {
+	*out = append(*out, fmt.Sprintf("%s %d %d %q", path, n.pass, n.count, n.word))
+	keys := make([]rune, 0, len(n.children))
+	for ch := range n.children {
+		keys = append(keys, ch)
+	}
+	slices.Sort(keys)
+	for _, ch := range keys {
+		dump(n.children[ch], path+string(ch), out)
+	}
+}
+
+func TestConcurrentAgainstTrie(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	words := randomWords(r, 80, "abé")
+	prefixes := randomWords(r, 30, "abé")
+	c, tr := NewConcurrent(WithCaseFolding()), New(WithCaseFolding())
+
+	for range 3000 {
+		w := words[r.Intn(len(words))]
+		if r.Intn(2) == 0 {
+			w = foldString(w) // another spelling of the same key
+		}
+		if r.Intn(3) == 0 {
+			if got, want := c.Delete(w), tr.Delete(w); got != want {
+				t.Fatalf("Delete(%q) = %v, want %v", w, got, want)
+			}
+		} else {
+			c.Insert(w)
+			tr.Insert(w)
+		}
+		if c.Len() != tr.Len() {
+			t.Fatalf("Len() = %d, want %d", c.Len(), tr.Len())
+		}
+		for _, p := range prefixes {
+			if c.CountPrefix(p) != tr.CountPrefix(p) || c.CountWord(p) != tr.CountWord(p) ||
+				c.Search(p) != tr.Search(p) || c.StartsWith(p) != tr.StartsWith(p) ||
+				!slices.Equal(c.KeysWithPrefix(p), tr.KeysWithPrefix(p)) {
+				t.Fatalf("ConcurrentTrie and Trie disagree on %q", p)
+			}
+		}
+	}
+}
+
+// TestConcurrentSnapshots runs writers against readers; run it with -race.
+// Readers check that a loaded root never changes, and that words no writer
+// touches are always found.
+func TestConcurrentSnapshots(t *testing.T) {
+	c := NewConcurrent()
+	stable := []string{"alpha", "alphabet", "beta"}
+	for _, w := range stable {
+		c.Insert(w)
+	}
+
+	var writers, readers sync.WaitGroup
+	done := make(chan struct{})
+	for i := range 4 {
+		writers.Add(1)
+		go func() {
+			defer writers.Done()
+			r := rand.New(rand.NewSource(int64(i)))
+			for range 2000 {
+				w := fmt.Sprintf("al%d", r.Intn(50))
+				if r.Intn(2) == 0 {
+					c.Insert(w)
+				} else {
+					c.Delete(w)
+				}
+			}
+		}()
+	}
+	for range 4 {
+		readers.Add(1)
+		go func() {
+			defer readers.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				root := c.root.Load()
+				var before, after []string
+				dump(root, "", &before)
+				for _, w := range stable {
+					if !c.Search(w) {
+						t.Errorf("Search(%q) = false during writes", w)
+						return
+					}
+				}
+				if keys := c.KeysWithPrefix("al"); !slices.IsSorted(keys) || len(keys) < 2 {
+					t.Errorf("KeysWithPrefix(al) = %q", keys)
+					return
+				}
+				dump(root, "", &after)
+				if !slices.Equal(before, after) {
+					t.Error("a published snapshot was modified")
+					return
+				}
+			}
+		}()
+	}
+	writers.Wait()
+	close(done)
+	readers.Wait()
+
+	if c.Len() < len(stable) || c.CountPrefix("") != c.Len() {
+		t.Fatalf("Len() = %d, CountPrefix(\"\") = %d", c.Len(), c.CountPrefix(""))
+	}
+}
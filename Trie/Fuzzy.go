@@ -0,0 +1,83 @@
+package trie
+
+import "slices"
+
+// SearchFuzzy returns every stored word whose Levenshtein distance from word
+// is at most maxEdits. The result is sorted.
+func (t *Trie) SearchFuzzy(word string, maxEdits int) []string {
+	return t.searchFuzzy(word, maxEdits, false)
+}
+
+// SearchFuzzyTransposed is like SearchFuzzy but also counts the swap of two
+// adjacent runes as a single edit (Damerau, optimal string alignment).
+func (t *Trie) SearchFuzzyTransposed(word string, maxEdits int) []string {
+	return t.searchFuzzy(word, maxEdits, true)
+}
+
+func (t *Trie) searchFuzzy(word string, maxEdits int, transpose bool) []string {
+	if maxEdits < 0 {
+		return nil
+	}
+	target := []rune(t.key(word))
+
+	// Row 0 of the DP matrix: distance from the empty prefix to target[:j].
+	row := make([]int, len(target)+1)
+	for j := range row {
+		row[j] = j
+	}
+
+	fz := &fuzzy{target: target, max: maxEdits, transpose: transpose}
+	if t.root.count > 0 && row[len(target)] <= maxEdits {
+		fz.found = append(fz.found, t.root.spelling(nil))
+	}
+	for ch, child := range t.root.children {
+		fz.walk(child, ch, 0, nil, row)
+	}
+	slices.Sort(fz.found)
+	return fz.found
+}
+
+type fuzzy struct {
+	target    []rune
+	max       int
+	transpose bool
+	path      []rune
+	found     []string
+}
+
+// walk computes the DP row for node n, reached via rune ch, from the rows of
+// its parent (prev) and grandparent (prevPrev, only used for transpositions).
+func (fz *fuzzy) walk(n *trienode, ch, prevCh rune, prevPrev, prev []int) {
+	fz.path = append(fz.path, ch)
+	defer func() { fz.path = fz.path[:len(fz.path)-1] }()
+
+	cols := len(fz.target) + 1
+	row := make([]int, cols)
+	row[0] = prev[0] + 1
+	rowMin := row[0]
+	for j := 1; j < cols; j++ {
+		cost := 1
+		if fz.target[j-1] == ch {
+			cost = 0
+		}
+		row[j] = min(prev[j]+1, row[j-1]+1, prev[j-1]+cost)
+		if fz.transpose && prevPrev != nil && j > 1 &&
+			fz.target[j-1] == prevCh && fz.target[j-2] == ch {
+			row[j] = min(row[j], prevPrev[j-2]+1)
+		}
+		rowMin = min(rowMin, row[j])
+	}
+
+	if n.count > 0 && row[cols-1] <= fz.max {
+		fz.found = append(fz.found, n.spelling(fz.path))
+	}
+
+	// A transposition can reach back one row, so with transpositions enabled
+	// the branch is only dead once the parent row can't help either.
+	if rowMin > fz.max && (!fz.transpose || slices.Min(prev) >= fz.max) {
+		return
+	}
+	for next, child := range n.children {
+		fz.walk(child, next, ch, prev, row)
+	}
+}
@@ -0,0 +1,115 @@
+package trie
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// randomWords returns n distinct words of 0 to 6 runes from alphabet.
+func randomWords(r *rand.Rand, n int, alphabet string) []string {
+	runes := []rune(alphabet)
+	seen := map[string]bool{}
+	var words []string
+	for len(words) < n {
+		w := make([]rune, r.Intn(7))
+		for i := range w {
+			w[i] = runes[r.Intn(len(runes))]
+		}
+		if !seen[string(w)] {
+			seen[string(w)] = true
+			words = append(words, string(w))
+		}
+	}
+	return words
+}
+
+// editDistance is the textbook full-matrix Levenshtein distance, or the
+// optimal string alignment distance with transpose set.
+func editDistance(a, b string, transpose bool) int {
+	s, t := []rune(a), []rune(b)
+	d := make([][]int, len(s)+1)
+	for i := range d {
+		d[i] = make([]int, len(t)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if transpose && i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(s)][len(t)]
+}
+
+func naiveFuzzy(words []string, word string, maxEdits int, transpose bool) []string {
+	var found []string
+	for _, w := range words {
+		if editDistance(strings.ToLower(w), strings.ToLower(word), transpose) <= maxEdits {
+			found = append(found, w)
+		}
+	}
+	slices.Sort(found)
+	return found
+}
+
+func TestSearchFuzzyAgainstBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	words := randomWords(r, 300, "abcé")
+	tr := New()
+	for _, w := range words {
+		tr.Insert(w)
+	}
+	queries := append(randomWords(r, 100, "abcdé"), "", "abab", "baba")
+
+	for _, q := range queries {
+		for maxEdits := -1; maxEdits <= 3; maxEdits++ {
+			for _, transpose := range []bool{false, true} {
+				got := tr.SearchFuzzy(q, maxEdits)
+				if transpose {
+					got = tr.SearchFuzzyTransposed(q, maxEdits)
+				}
+				want := naiveFuzzy(words, q, maxEdits, transpose)
+				if !slices.Equal(got, want) {
+					t.Fatalf("fuzzy(%q, %d, transpose=%v) = %q, want %q", q, maxEdits, transpose, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestSearchFuzzyTransposition(t *testing.T) {
+	tr := New()
+	tr.Insert("receive")
+	if got := tr.SearchFuzzy("recieve", 1); len(got) != 0 {
+		t.Errorf("SearchFuzzy found %q: a swap costs two edits", got)
+	}
+	if got := tr.SearchFuzzyTransposed("recieve", 1); !slices.Equal(got, []string{"receive"}) {
+		t.Errorf("SearchFuzzyTransposed = %q, want [receive]", got)
+	}
+}
+
+func TestSearchFuzzyFoldsCase(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	words := randomWords(r, 200, "abcd")
+	tr := New(WithCaseFolding())
+	for _, w := range words {
+		tr.Insert(w)
+	}
+	for _, q := range randomWords(r, 50, "aBcD") {
+		got, want := tr.SearchFuzzy(q, 1), naiveFuzzy(words, q, 1, false)
+		if !slices.Equal(got, want) {
+			t.Fatalf("SearchFuzzy(%q, 1) = %q, want %q", q, got, want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package trie
+
+import (
+	"errors"
+	"slices"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrBadPattern is returned by Match when the pattern is malformed.
+var ErrBadPattern = errors.New("syntax error in pattern")
+
+type tokenKind uint8
+
+const (
+	tokLiteral tokenKind = iota
+	tokAny               // ?
+	tokStar              // *
+	tokClass             // [...]
+)
+
+type runeRange struct {
+	lo, hi rune
+}
+
+type token struct {
+	kind   tokenKind
+	r      rune
+	ranges []runeRange
+	negate bool
+}
+
+// matches reports whether the trie rune ch satisfies tk. With fold set, ch is
+// a folded rune and a class also accepts it if any of its case variants is in
+// the class.
+func (tk *token) matches(ch rune, fold bool) bool {
+	switch tk.kind {
+	case tokLiteral:
+		return ch == tk.r
+	case tokAny:
+		return true
+	case tokClass:
+		in := tk.inClass(ch)
+		if fold {
+			for f := unicode.SimpleFold(ch); !in && f != ch; f = unicode.SimpleFold(f) {
+				in = tk.inClass(f)
+			}
+		}
+		return in != tk.negate
+	}
+	return false
+}
+
+func (tk *token) inClass(ch rune) bool {
+	for _, rr := range tk.ranges {
+		if rr.lo <= ch && ch <= rr.hi {
+			return true
+		}
+	}
+	return false
+}
+
+// Match returns the stored words matching pattern, sorted.
+//
+// The pattern syntax is:
+//
+//	?       any single rune
+//	*       any sequence of runes, including the empty one
+//	[abc]   one rune from the set; ranges like [a-z] are allowed
+//	[!a-z]  one rune not in the set ([^a-z] works too)
+//	\c      the rune c, literally
+//
+// If the trie folds case or normalizes keys, the pattern is matched against
+// the transformed keys, and the original spellings are returned.
+func (t *Trie) Match(pattern string) ([]string, error) {
+	if t.normalize != nil {
+		pattern = t.normalize(pattern)
+	}
+	tokens, err := compilePattern(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if t.fold {
+		for i := range tokens {
+			tokens[i].r = foldRune(tokens[i].r)
+		}
+	}
+
+	m := &matcher{tokens: tokens, fold: t.fold, seen: make(map[matchState]bool)}
+	m.walk(t.root, 0)
+	slices.Sort(m.found)
+	return m.found, nil
+}
+
+type matchState struct {
+	n   *trienode
+	pos int
+}
+
+type matcher struct {
+	tokens []token
+	fold   bool
+	path   []rune
+	found  []string
+	// seen stops several stars from reaching the same node at the same
+	// pattern position more than once, which would duplicate results and
+	// blow up exponentially.
+	seen map[matchState]bool
+}
+
+func (m *matcher) walk(n *trienode, pos int) {
+	st := matchState{n, pos}
+	if m.seen[st] {
+		return
+	}
+	m.seen[st] = true
+
+	if pos == len(m.tokens) {
+		if n.count > 0 {
+			m.found = append(m.found, n.spelling(m.path))
+		}
+		return
+	}
+
+	tk := &m.tokens[pos]
+	if tk.kind == tokStar {
+		// Either the star matches nothing, or it eats one more rune.
+		m.walk(n, pos+1)
+		for ch, child := range n.children {
+			m.path = append(m.path, ch)
+			m.walk(child, pos)
+			m.path = m.path[:len(m.path)-1]
+		}
+		return
+	}
+
+	if tk.kind == tokLiteral {
+		if child, ok := n.children[tk.r]; ok {
+			m.path = append(m.path, tk.r)
+			m.walk(child, pos+1)
+			m.path = m.path[:len(m.path)-1]
+		}
+		return
+	}
+
+	for ch, child := range n.children {
+		if tk.matches(ch, m.fold) {
+			m.path = append(m.path, ch)
+			m.walk(child, pos+1)
+			m.path = m.path[:len(m.path)-1]
+		}
+	}
+}
+
+func compilePattern(pattern string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(pattern); {
+		ch, size := utf8.DecodeRuneInString(pattern[i:])
+		i += size
+
+		switch ch {
+		case '*':
+			// Consecutive stars are equivalent to a single one.
+			if len(tokens) == 0 || tokens[len(tokens)-1].kind != tokStar {
+				tokens = append(tokens, token{kind: tokStar})
+			}
+		case '?':
+			tokens = append(tokens, token{kind: tokAny})
+		case '\\':
+			if i >= len(pattern) {
+				return nil, ErrBadPattern
+			}
+			ch, size = utf8.DecodeRuneInString(pattern[i:])
+			i += size
+			tokens = append(tokens, token{kind: tokLiteral, r: ch})
+		case '[':
+			tk, n, err := compileClass(pattern[i:])
+			if err != nil {
+				return nil, err
+			}
+			i += n
+			tokens = append(tokens, tk)
+		default:
+			tokens = append(tokens, token{kind: tokLiteral, r: ch})
+		}
+	}
+	return tokens, nil
+}
+
+// compileClass parses a character class whose opening '[' has already been
+// consumed. It returns the token and the number of bytes used, ']' included.
+func compileClass(s string) (token, int, error) {
+	tk := token{kind: tokClass}
+	i := 0
+	if i < len(s) && (s[i] == '!' || s[i] == '^') {
+		tk.negate = true
+		i++
+	}
+
+	readRune := func() (rune, error) {
+		if i >= len(s) {
+			return 0, ErrBadPattern
+		}
+		ch, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if ch == '\\' {
+			if i >= len(s) {
+				return 0, ErrBadPattern
+			}
+			ch, size = utf8.DecodeRuneInString(s[i:])
+			i += size
+		}
+		return ch, nil
+	}
+
+	for {
+		if i >= len(s) {
+			return tk, 0, ErrBadPattern
+		}
+		// A ']' right after the opening bracket is taken literally.
+		if s[i] == ']' && len(tk.ranges) > 0 {
+			return tk, i + 1, nil
+		}
+		lo, err := readRune()
+		if err != nil {
+			return tk, 0, err
+		}
+		hi := lo
+		if i+1 < len(s) && s[i] == '-' && s[i+1] != ']' {
+			i++
+			if hi, err = readRune(); err != nil {
+				return tk, 0, err
+			}
+			if hi < lo {
+				return tk, 0, ErrBadPattern
+			}
+		}
+		tk.ranges = append(tk.ranges, runeRange{lo, hi})
+	}
+}
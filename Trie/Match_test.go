@@ -0,0 +1,118 @@
+package trie
+
+import (
+	"errors"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// globMatch is a naive backtracking matcher over the runes of w, kept apart
+// from the trie's tokenizer. It only knows the syntax randomPattern emits.
+func globMatch(p, w []rune) bool {
+	if len(p) == 0 {
+		return len(w) == 0
+	}
+	switch p[0] {
+	case '*':
+		for i := 0; i <= len(w); i++ {
+			if globMatch(p[1:], w[i:]) {
+				return true
+			}
+		}
+		return false
+	case '?':
+		return len(w) > 0 && globMatch(p[1:], w[1:])
+	case '\\':
+		return len(w) > 0 && w[0] == p[1] && globMatch(p[2:], w[1:])
+	case '[':
+		end := slices.Index(p, ']')
+		class, negate := p[1:end], false
+		if class[0] == '!' || class[0] == '^' {
+			class, negate = class[1:], true
+		}
+		in := false
+		for i := 0; i < len(class); i++ {
+			lo, hi := class[i], class[i]
+			if i+2 < len(class) && class[i+1] == '-' {
+				hi = class[i+2]
+				i += 2
+			}
+			in = in || (len(w) > 0 && lo <= w[0] && w[0] <= hi)
+		}
+		return len(w) > 0 && in != negate && globMatch(p[end+1:], w[1:])
+	}
+	return len(w) > 0 && w[0] == p[0] && globMatch(p[1:], w[1:])
+}
+
+func randomPattern(r *rand.Rand) string {
+	parts := []string{"a", "b", "é", "?", "*", "[a-b]", "[!ac]", "[^é]", "[bé]", `\a`, `\*`}
+	var p strings.Builder
+	for range r.Intn(5) {
+		p.WriteString(parts[r.Intn(len(parts))])
+	}
+	return p.String()
+}
+
+func TestMatchAgainstBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	words := append(randomWords(r, 300, "abcé"), "*", "a*b")
+	tr := New()
+	for _, w := range words {
+		tr.Insert(w)
+	}
+
+	for range 2000 {
+		pattern := randomPattern(r)
+		got, err := tr.Match(pattern)
+		if err != nil {
+			t.Fatalf("Match(%q): %v", pattern, err)
+		}
+		var want []string
+		for _, w := range words {
+			if globMatch([]rune(pattern), []rune(w)) {
+				want = append(want, w)
+			}
+		}
+		slices.Sort(want)
+		if !slices.Equal(got, want) {
+			t.Fatalf("Match(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
+
+func TestMatchFoldsCase(t *testing.T) {
+	tr := New(WithCaseFolding())
+	for _, w := range []string{"Apple", "apricot", "BANANA", "Straße", "ÉCOLE"} {
+		tr.Insert(w)
+	}
+	cases := map[string][]string{
+		"ap*":        {"Apple", "apricot"},
+		"AP*":        {"Apple", "apricot"},
+		"[A-A]p*":    {"Apple", "apricot"},
+		"[!b]*":      {"Apple", "Straße", "apricot", "ÉCOLE"},
+		"banana":     {"BANANA"},
+		"STRASSE":    nil, // simple folding does not expand ß
+		"stra?e":     {"Straße"},
+		"[é]cole":    {"ÉCOLE"},
+		"*[O]L?":     {"ÉCOLE"},
+		"b[a-z]nana": {"BANANA"},
+	}
+	for pattern, want := range cases {
+		got, err := tr.Match(pattern)
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("Match(%q) = %q, %v; want %q", pattern, got, err, want)
+		}
+	}
+}
+
+func TestMatchBadPattern(t *testing.T) {
+	tr := New()
+	tr.Insert("a")
+	for _, pattern := range []string{`a\`, "[", "[a", "[]", "[z-a]", `[a\`} {
+		if _, err := tr.Match(pattern); !errors.Is(err, ErrBadPattern) {
+			t.Errorf("Match(%q) error = %v, want ErrBadPattern", pattern, err)
+		}
+	}
+}
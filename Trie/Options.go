@@ -0,0 +1,84 @@
+package trie
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Option configures a Trie created with New.
+type Option func(*Trie)
+
+// WithCaseFolding makes keys match regardless of case, using Unicode simple
+// case folding: "Straße" and "STRAßE" are the same key.
+func WithCaseFolding() Option {
+	return func(t *Trie) {
+		t.fold = true
+	}
+}
+
+// WithNormalizer applies fn to every key before it is used. A typical choice
+// is norm.NFC.String from golang.org/x/text/unicode/norm, which makes the
+// composed and decomposed spellings of "Café" the same key.
+func WithNormalizer(fn func(string) string) Option {
+	return func(t *Trie) {
+		t.normalize = fn
+	}
+}
+
+// keyOptions holds the key rewriting shared by Trie and ConcurrentTrie.
+type keyOptions struct {
+	fold      bool
+	normalize func(string) string
+}
+
+// transforms reports whether keys are rewritten before use, in which case
+// stored words remember their original spelling.
+func (o *keyOptions) transforms() bool {
+	return o.fold || o.normalize != nil
+}
+
+// key returns the form of s used to walk the trie.
+func (o *keyOptions) key(s string) string {
+	if o.normalize != nil {
+		s = o.normalize(s)
+	}
+	if o.fold {
+		s = foldString(s)
+	}
+	return s
+}
+
+// spelling returns the word stored at n, which was reached through path.
+func (n *trienode) spelling(path []rune) string {
+	if n.word != "" {
+		return n.word
+	}
+	return string(path)
+}
+
+func foldString(s string) string {
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c >= utf8.RuneSelf || ('A' <= c && c <= 'Z') {
+			return strings.Map(foldRune, s)
+		}
+	}
+	return s
+}
+
+// foldRune maps r to the smallest rune of its case folding orbit, so all the
+// case variants of a letter share one key. Orbits holding an ASCII letter,
+// like {K, k, U+212A KELVIN SIGN}, map to the lower case ASCII letter instead,
+// which keeps plain ASCII keys unchanged.
+func foldRune(r rune) rune {
+	least := r
+	if r >= utf8.RuneSelf {
+		for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+			least = min(least, f)
+		}
+	}
+	if 'A' <= least && least <= 'Z' {
+		least += 'a' - 'A'
+	}
+	return least
+}
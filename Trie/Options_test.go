@@ -0,0 +1,86 @@
+package trie
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// composeAcute stands in for NFC normalization: it composes "e" and "E"
+// followed by a combining acute accent.
+var composeAcute = strings.NewReplacer("é", "é", "É", "É").Replace
+
+func TestOptionsApplyEverywhere(t *testing.T) {
+	nfd, nfc := "Café", "Café"
+	tr := New(WithCaseFolding(), WithNormalizer(composeAcute))
+	tr.Insert(nfd)
+	tr.Insert("CAFÉ")
+	tr.Insert("Straße")
+
+	for _, key := range []string{nfd, nfc, "café", "CAFÉ", "cAfÉ"} {
+		if !tr.Search(key) || tr.CountWord(key) != 2 {
+			t.Errorf("%q: Search = %v, CountWord = %d", key, tr.Search(key), tr.CountWord(key))
+		}
+	}
+	for _, prefix := range []string{"caf", "CAF", "café", "STRA"} {
+		if !tr.StartsWith(prefix) {
+			t.Errorf("StartsWith(%q) = false", prefix)
+		}
+	}
+	if tr.Search("Strasse") {
+		t.Error(`"Strasse" found: simple folding keeps ß`)
+	}
+
+	// The first spelling inserted is the one returned.
+	if got := tr.KeysWithPrefix("CA"); !slices.Equal(got, []string{nfd}) {
+		t.Errorf("KeysWithPrefix = %q, want [%q]", got, nfd)
+	}
+	if got, _ := tr.Match("c?f?"); !slices.Equal(got, []string{nfd}) {
+		t.Errorf("Match = %q, want [%q]", got, nfd)
+	}
+	if got := tr.SearchFuzzy("kafe", 2); !slices.Equal(got, []string{nfd}) {
+		t.Errorf("SearchFuzzy = %q, want [%q]", got, nfd)
+	}
+
+	if !tr.Delete("café") || !tr.Delete("CAFÉ") || tr.Delete(nfc) {
+		t.Error("Delete did not remove exactly the two stored copies")
+	}
+	if tr.StartsWith("caf") || tr.Len() != 1 {
+		t.Errorf("after Delete: StartsWith = %v, Len = %d", tr.StartsWith("caf"), tr.Len())
+	}
+
+	// Once a word is gone, the next spelling inserted is remembered.
+	tr.Insert("CAFÉ")
+	if got := tr.KeysWithPrefix(""); !slices.Equal(got, []string{"CAFÉ", "Straße"}) {
+		t.Errorf("KeysWithPrefix = %q", got)
+	}
+}
+
+func TestConcurrentOptions(t *testing.T) {
+	c := NewConcurrent(WithCaseFolding(), WithNormalizer(composeAcute))
+	c.Insert("Café")
+	if !c.Search("CAFÉ") || !c.StartsWith("café") || c.CountPrefix("CA") != 1 {
+		t.Error("ConcurrentTrie does not apply its options")
+	}
+	if got := c.KeysWithPrefix("c"); !slices.Equal(got, []string{"Café"}) {
+		t.Errorf("KeysWithPrefix = %q", got)
+	}
+	if !c.Delete("café") || c.Len() != 0 {
+		t.Error("Delete did not use the options")
+	}
+}
+
+func TestFoldRune(t *testing.T) {
+	// Every case variant of a letter folds to the same rune.
+	for _, group := range []string{"kKK", "sSſ", "µΜμ", "ǅǄǆ", "σςΣ"} {
+		runes := []rune(group)
+		for _, r := range runes {
+			if foldRune(r) != foldRune(runes[0]) {
+				t.Errorf("foldRune(%q) = %q, want %q", r, foldRune(r), foldRune(runes[0]))
+			}
+		}
+	}
+	if foldString("abc") != "abc" || foldString("ÀB") != foldString("àb") {
+		t.Error("foldString")
+	}
+}
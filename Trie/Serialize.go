@@ -0,0 +1,277 @@
+package trie
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// The encoding is a magic string and a version byte, followed by the nodes in
+// preorder. Each node is written as
+//
+//	uvarint count     times the word ending here was inserted
+//	uvarint length    only if count > 0: length of the original spelling
+//	bytes             the original spelling, empty unless keys are transformed
+//	uvarint children  number of children
+//	children          for each child, by increasing rune: uvarint rune, node
+//
+// Prefix counts are not stored; they are rebuilt while loading.
+const (
+	magic         = "GTRI"
+	formatVersion = 1
+)
+
+var errMalformed = errors.New("trie: malformed encoding")
+
+// WriteTo writes the trie to w in a compact binary form that ReadFrom loads
+// back. It implements io.WriterTo.
+func (t *Trie) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	cw.writeString(magic)
+	cw.writeByte(formatVersion)
+	writeNode(cw, t.root)
+	if err := cw.w.Flush(); err != nil && cw.err == nil {
+		cw.err = err
+	}
+	return cw.n, cw.err
+}
+
+func writeNode(cw *countingWriter, n *trienode) {
+	cw.writeUvarint(uint64(n.count))
+	if n.count > 0 {
+		cw.writeUvarint(uint64(len(n.word)))
+		cw.writeString(n.word)
+	}
+	cw.writeUvarint(uint64(len(n.children)))
+
+	keys := make([]rune, 0, len(n.children))
+	for ch := range n.children {
+		keys = append(keys, ch)
+	}
+	slices.Sort(keys)
+	for _, ch := range keys {
+		cw.writeUvarint(uint64(ch))
+		writeNode(cw, n.children[ch])
+	}
+}
+
+// ReadFrom replaces the content of the trie with the encoding read from r.
+// It implements io.ReaderFrom. On error the trie is left unchanged. The
+// encoding must come from a trie with the same options, since keys are
+// stored already transformed.
+func (t *Trie) ReadFrom(r io.Reader) (int64, error) {
+	br, ok := r.(io.ByteReader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	cr := &countingReader{r: br}
+
+	var head [len(magic) + 1]byte
+	for i := range head {
+		b, err := cr.ReadByte()
+		if err != nil {
+			return cr.n, unexpected(err)
+		}
+		head[i] = b
+	}
+	if string(head[:len(magic)]) != magic {
+		return cr.n, errors.New("trie: not a trie encoding")
+	}
+	if version := head[len(magic)]; version != formatVersion {
+		return cr.n, fmt.Errorf("trie: unsupported encoding version %d", version)
+	}
+
+	root, err := readNode(cr)
+	if err != nil {
+		return cr.n, err
+	}
+	t.root = root
+	return cr.n, nil
+}
+
+func readNode(cr *countingReader) (*trienode, error) {
+	count, err := cr.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	var word string
+	if count > 0 {
+		if word, err = cr.readString(); err != nil {
+			return nil, err
+		}
+	}
+	nchildren, err := cr.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	// The map is sized lazily below so a corrupt length can't force a huge
+	// allocation up front.
+	if int(count) < 0 || nchildren > utf8.MaxRune+1 {
+		return nil, errMalformed
+	}
+
+	n := &trienode{children: make(map[rune]*trienode, min(nchildren, 64)), count: int(count), word: word}
+	n.pass = n.count
+	last := rune(-1)
+	for range nchildren {
+		ch, err := cr.readUvarint()
+		if err != nil {
+			return nil, err
+		}
+		if ch > utf8.MaxRune || rune(ch) <= last {
+			return nil, errMalformed
+		}
+		last = rune(ch)
+
+		child, err := readNode(cr)
+		if err != nil {
+			return nil, err
+		}
+		if child.pass == 0 {
+			return nil, errMalformed
+		}
+		n.children[last] = child
+		n.pass += child.pass
+	}
+	return n, nil
+}
+
+// LoadWords builds a trie from a newline-separated word list. Empty lines are
+// skipped. It is faster than calling Insert for every word when the list is
+// sorted, because the path shared with the previous word is not walked again.
+func LoadWords(r io.Reader, opts ...Option) (*Trie, error) {
+	t := New(opts...)
+	sc := bufio.NewScanner(r)
+
+	var prev, word []rune
+	stack := []*trienode{t.root}
+	for sc.Scan() {
+		line := strings.TrimSuffix(sc.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		word = word[:0]
+		for _, ch := range t.key(line) {
+			word = append(word, ch)
+		}
+
+		common := 0
+		for common < len(prev) && common < len(word) && prev[common] == word[common] {
+			common++
+		}
+		stack = stack[:common+1]
+		curr := stack[common]
+		for _, ch := range word[common:] {
+			next, exist := curr.children[ch]
+			if !exist {
+				next = &trienode{children: make(map[rune]*trienode)}
+				curr.children[ch] = next
+			}
+			curr = next
+			stack = append(stack, curr)
+		}
+
+		for _, n := range stack {
+			n.pass++
+		}
+		curr.count++
+		if t.transforms() && curr.word == "" {
+			curr.word = line
+		}
+		prev, word = word, prev
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func unexpected(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+	buf [binary.MaxVarintLen64]byte
+}
+
+func (cw *countingWriter) writeString(s string) {
+	if cw.err != nil {
+		return
+	}
+	n, err := cw.w.WriteString(s)
+	cw.n += int64(n)
+	cw.err = err
+}
+
+func (cw *countingWriter) writeByte(b byte) {
+	if cw.err != nil {
+		return
+	}
+	cw.err = cw.w.WriteByte(b)
+	if cw.err == nil {
+		cw.n++
+	}
+}
+
+func (cw *countingWriter) writeUvarint(v uint64) {
+	if cw.err != nil {
+		return
+	}
+	n, err := cw.w.Write(binary.AppendUvarint(cw.buf[:0], v))
+	cw.n += int64(n)
+	cw.err = err
+}
+
+type countingReader struct {
+	r io.ByteReader
+	n int64
+}
+
+func (cr *countingReader) ReadByte() (byte, error) {
+	b, err := cr.r.ReadByte()
+	if err == nil {
+		cr.n++
+	}
+	return b, err
+}
+
+func (cr *countingReader) readUvarint() (uint64, error) {
+	v, err := binary.ReadUvarint(cr)
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, io.ErrUnexpectedEOF
+		}
+		return 0, errMalformed
+	}
+	return v, nil
+}
+
+// readString reads a uvarint length followed by that many bytes. The buffer
+// grows as bytes arrive, so a corrupt length fails on EOF instead of
+// allocating up front.
+func (cr *countingReader) readString() (string, error) {
+	n, err := cr.readUvarint()
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for ; n > 0; n-- {
+		b, err := cr.ReadByte()
+		if err != nil {
+			return "", unexpected(err)
+		}
+		sb.WriteByte(b)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,117 @@
+package trie
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// sameNodes reports whether the subtrees a and b store the same words with
+// the same counts and spellings.
+func sameNodes(a, b *trienode) bool {
+	if a.count != b.count || a.pass != b.pass || a.word != b.word || len(a.children) != len(b.children) {
+		return false
+	}
+	for ch, ca := range a.children {
+		cb, ok := b.children[ch]
+		if !ok || !sameNodes(ca, cb) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestWriteReadRoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	words := append(randomWords(r, 500, "abcé日"), "", "abc", "abc")
+	for _, opts := range [][]Option{nil, {WithCaseFolding()}} {
+		tr := New(opts...)
+		for _, w := range words {
+			tr.Insert(w)
+			if r.Intn(2) == 0 {
+				tr.Insert(strings.ToUpper(w))
+			}
+		}
+
+		var buf bytes.Buffer
+		n, err := tr.WriteTo(&buf)
+		if err != nil || n != int64(buf.Len()) {
+			t.Fatalf("WriteTo = %d, %v; wrote %d bytes", n, err, buf.Len())
+		}
+		encoded := slices.Clone(buf.Bytes())
+
+		loaded := New(opts...)
+		if n, err := loaded.ReadFrom(&buf); err != nil || n != int64(len(encoded)) {
+			t.Fatalf("ReadFrom = %d, %v; want %d, nil", n, err, len(encoded))
+		}
+		if !sameNodes(tr.root, loaded.root) {
+			t.Fatal("loaded trie differs")
+		}
+		buf.Reset()
+		loaded.WriteTo(&buf)
+		if !bytes.Equal(buf.Bytes(), encoded) {
+			t.Fatal("encoding changed after a round trip")
+		}
+	}
+}
+
+func TestReadRejects(t *testing.T) {
+	tr := New()
+	tr.Insert("kept")
+	var buf bytes.Buffer
+	tr.WriteTo(&buf)
+	valid := buf.Bytes()
+
+	for i := range len(valid) {
+		if _, err := New().ReadFrom(bytes.NewReader(valid[:i])); !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("truncated to %d bytes: err = %v", i, err)
+		}
+	}
+	cases := map[string][]byte{
+		"magic":      []byte("XTRI\x01\x00\x00"),
+		"version 0":  []byte("GTRI\x00\x00\x00"),
+		"version 2":  []byte("GTRI\x02\x00\x00"),
+		"rune order": []byte("GTRI\x01\x00\x02b\x01\x00\x00a\x01\x00\x00"),
+		"empty kid":  []byte("GTRI\x01\x00\x01a\x00\x00"),
+		"bad rune":   []byte("GTRI\x01\x00\x01\xff\xff\xff\xff\x0f\x01\x00\x00"),
+	}
+	for name, data := range cases {
+		if _, err := tr.ReadFrom(bytes.NewReader(data)); err == nil {
+			t.Errorf("%s: ReadFrom succeeded", name)
+		}
+	}
+	if !tr.Search("kept") || tr.Len() != 1 {
+		t.Error("a failed ReadFrom changed the trie")
+	}
+}
+
+func TestLoadWords(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	words := append(randomWords(r, 500, "abcé"), "abc", "abc", "Abc")
+	for _, sorted := range []bool{false, true} {
+		list := slices.Clone(words)
+		if sorted {
+			slices.Sort(list)
+		}
+		for _, opts := range [][]Option{nil, {WithCaseFolding()}} {
+			want := New(opts...)
+			for _, w := range list {
+				if w != "" {
+					want.Insert(w)
+				}
+			}
+			input := strings.Join(list, "\n") + "\r\n\n"
+			got, err := LoadWords(strings.NewReader(input), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !sameNodes(got.root, want.root) {
+				t.Fatalf("sorted=%v: LoadWords differs from Insert", sorted)
+			}
+		}
+	}
+}
@@ -1,51 +1,147 @@
 package trie
 
+import "slices"
+
 type trienode struct {
 	children map[rune]*trienode
-	end      bool
+	pass     int    // words stored in this subtree, duplicates included
+	count    int    // times the word ending here was inserted
+	word     string // first spelling inserted, set only when keys are transformed
 }
 
 type Trie struct {
 	root *trienode
+	keyOptions
 }
 
-func New() *Trie {
-	return &Trie{
+func New(opts ...Option) *Trie {
+	t := &Trie{
 		root: &trienode{children: make(map[rune]*trienode)},
 	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
 }
 
 func (t *Trie) Search(key string) bool {
-
-	curr := t.root
-	for _, ch := range key {
-		if _, exist := curr.children[ch]; !exist {
-			return false
-		}
-		curr = curr.children[ch]
-	}
-	return curr.end
+	n := t.find(t.key(key))
+	return n != nil && n.count > 0
 }
 
+// Insert adds str to the trie. Inserting the same word again increases its
+// count, see CountWord. If the trie folds case or normalizes keys, the first
+// spelling inserted is the one returned by enumeration methods.
 func (t *Trie) Insert(str string) {
+	key := t.key(str)
 	curr := t.root
-	for _, ch := range str {
+	curr.pass++
+	for _, ch := range key {
 		if _, exist := curr.children[ch]; !exist {
 			curr.children[ch] = &trienode{children: make(map[rune]*trienode)}
 		}
 		curr = curr.children[ch]
+		curr.pass++
+	}
+	curr.count++
+	if t.transforms() && curr.word == "" {
+		curr.word = str
 	}
-	curr.end = true
 }
 
 // Find only the prefix it return true if the prefix is in the trie, not if the last rune in prefix is the end of the world
 func (t *Trie) StartsWith(prefix string) bool {
+	return t.find(t.key(prefix)) != nil
+}
+
+// Delete removes one occurrence of key and prunes the nodes left without
+// words. It returns false if key was not stored.
+func (t *Trie) Delete(key string) bool {
+	path := []*trienode{t.root}
+	runes := []rune(t.key(key))
 	curr := t.root
-	for _, ch := range prefix {
-		if _, exist := curr.children[ch]; !exist {
+	for _, ch := range runes {
+		next, exist := curr.children[ch]
+		if !exist {
 			return false
 		}
-		curr = curr.children[ch]
+		curr = next
+		path = append(path, curr)
+	}
+	if curr.count == 0 {
+		return false
+	}
+	curr.count--
+	if curr.count == 0 {
+		curr.word = ""
+	}
+
+	for i, n := range path {
+		n.pass--
+		if n.pass == 0 && i > 0 {
+			// Nothing below here is stored any more.
+			delete(path[i-1].children, runes[i-1])
+			break
+		}
 	}
 	return true
 }
+
+// KeysWithPrefix returns every stored word starting with prefix, sorted.
+// A word inserted several times is returned once.
+func (t *Trie) KeysWithPrefix(prefix string) []string {
+	prefix = t.key(prefix)
+	curr := t.find(prefix)
+	if curr == nil {
+		return nil
+	}
+
+	var words []string
+	var collect func(n *trienode, path []rune)
+	collect = func(n *trienode, path []rune) {
+		if n.count > 0 {
+			words = append(words, n.spelling(path))
+		}
+		for ch, child := range n.children {
+			collect(child, append(path, ch))
+		}
+	}
+	collect(curr, []rune(prefix))
+	slices.Sort(words)
+	return words
+}
+
+// CountPrefix returns how many stored words start with prefix, counting
+// duplicates.
+func (t *Trie) CountPrefix(prefix string) int {
+	if n := t.find(t.key(prefix)); n != nil {
+		return n.pass
+	}
+	return 0
+}
+
+// CountWord returns how many times word was inserted and not yet deleted.
+func (t *Trie) CountWord(word string) int {
+	if n := t.find(t.key(word)); n != nil {
+		return n.count
+	}
+	return 0
+}
+
+// Len returns the number of stored words, counting duplicates.
+func (t *Trie) Len() int {
+	return t.root.pass
+}
+
+// find returns the node reached by key, which must already be transformed.
+func (t *Trie) find(key string) *trienode {
+	curr := t.root
+	for _, ch := range key {
+		next, exist := curr.children[ch]
+		if !exist {
+			return nil
+		}
+		curr = next
+	}
+	return curr
+}
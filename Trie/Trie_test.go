@@ -0,0 +1,80 @@
+package trie
+
+import (
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// checkCounts compares every count of tr with a brute-force count over the
+// multiset model.
+func checkCounts(t *testing.T, tr *Trie, model map[string]int, prefixes []string) {
+	t.Helper()
+	total := 0
+	var keys []string
+	for w, n := range model {
+		total += n
+		keys = append(keys, w)
+		if got := tr.CountWord(w); got != n {
+			t.Fatalf("CountWord(%q) = %d, want %d", w, got, n)
+		}
+	}
+	if tr.Len() != total {
+		t.Fatalf("Len() = %d, want %d", tr.Len(), total)
+	}
+	for _, p := range prefixes {
+		want := 0
+		for w, n := range model {
+			if strings.HasPrefix(w, p) {
+				want += n
+			}
+		}
+		if got := tr.CountPrefix(p); got != want {
+			t.Fatalf("CountPrefix(%q) = %d, want %d", p, got, want)
+		}
+		if got := tr.StartsWith(p); got != (want > 0) && p != "" {
+			t.Fatalf("StartsWith(%q) = %v with %d words under it", p, got, want)
+		}
+	}
+	slices.Sort(keys)
+	if got := tr.KeysWithPrefix(""); !slices.Equal(got, keys) {
+		t.Fatalf("KeysWithPrefix(\"\") = %q, want %q", got, keys)
+	}
+}
+
+func TestCountsAgainstModel(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	words := randomWords(r, 60, "abé")
+	prefixes := randomWords(r, 40, "abé")
+	tr := New()
+	model := map[string]int{}
+
+	for range 3000 {
+		w := words[r.Intn(len(words))]
+		if r.Intn(3) == 0 {
+			if got, want := tr.Delete(w), model[w] > 0; got != want {
+				t.Fatalf("Delete(%q) = %v, want %v", w, got, want)
+			}
+			if model[w]--; model[w] <= 0 {
+				delete(model, w)
+			}
+		} else {
+			tr.Insert(w)
+			model[w]++
+		}
+		if tr.Search(w) != (model[w] > 0) {
+			t.Fatalf("Search(%q) = %v with count %d", w, !(model[w] > 0), model[w])
+		}
+		checkCounts(t, tr, model, prefixes)
+	}
+
+	for w, n := range model {
+		for range n {
+			tr.Delete(w)
+		}
+	}
+	if tr.Len() != 0 || len(tr.root.children) != 0 {
+		t.Fatalf("empty trie keeps %d words and %d root children", tr.Len(), len(tr.root.children))
+	}
+}
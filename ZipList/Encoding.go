@@ -0,0 +1,214 @@
+package ziplist
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// prevlen reads the previous-entry length at the start of the entry at
+// offset, and returns it with the size of the field.
+func (zl *Ziplist) prevlen(offset int) (prev, field int, err error) {
+	if offset >= len(zl.bytes) {
+		return 0, 0, fmt.Errorf("out of bounds")
+	}
+	if zl.bytes[offset] < prevlenBig {
+		return int(zl.bytes[offset]), 1, nil
+	}
+	if zl.bytes[offset] != prevlenBig || offset+5 > len(zl.bytes) {
+		return 0, 0, fmt.Errorf("malformed previous entry length")
+	}
+	return int(binary.LittleEndian.Uint32(zl.bytes[offset+1 : offset+5])), 5, nil
+}
+
+func appendPrevlen(dst []byte, n int) []byte {
+	if n < prevlenBig {
+		return append(dst, byte(n))
+	}
+	dst = append(dst, prevlenBig)
+	return binary.LittleEndian.AppendUint32(dst, uint32(n))
+}
+
+// layout decodes the entry at offset. It returns the type, with the compact
+// string tags folded into TYPE_STRING, and the position and length of the
+// payload.
+func (zl *Ziplist) layout(offset int) (typ byte, dataStart, dataLen int, err error) {
+	_, field, err := zl.prevlen(offset)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	offset += field
+	if offset >= len(zl.bytes) {
+		return 0, 0, 0, fmt.Errorf("out of bounds")
+	}
+	typ = zl.bytes[offset]
+	dataStart = offset + 1
+
+	switch {
+	case typ >= TYPE_IMM && typ <= TYPE_IMMMAX:
+		dataLen = 0
+
+	case typ&0xc0 == TYPE_STR6:
+		dataLen = int(typ & 0x3f)
+		typ = TYPE_STRING
+
+	case typ&0xc0 == TYPE_STR14:
+		if dataStart+1 > len(zl.bytes) {
+			return 0, 0, 0, fmt.Errorf("malformed string header")
+		}
+		dataLen = int(typ&0x3f)<<8 | int(zl.bytes[dataStart])
+		dataStart++
+		typ = TYPE_STRING
+
+	case typ == TYPE_STRING || typ == TYPE_BLOB:
+		if dataStart+4 > len(zl.bytes) {
+			return 0, 0, 0, fmt.Errorf("malformed string header")
+		}
+		dataLen = int(binary.LittleEndian.Uint32(zl.bytes[dataStart : dataStart+4]))
+		dataStart += 4
+
+	default:
+		if dataLen = fixedSize(typ); dataLen == 0 {
+			return 0, 0, 0, fmt.Errorf("unknown type: %x", typ)
+		}
+	}
+
+	if dataLen > len(zl.bytes)-dataStart {
+		return 0, 0, 0, fmt.Errorf("entry overflows the list")
+	}
+	return typ, dataStart, dataLen, nil
+}
+
+// fixedSize returns the payload size of the fixed-width types, 0 otherwise.
+func fixedSize(typ byte) int {
+	switch typ {
+	case TYPE_UINT8, TYPE_INT8, TYPE_BOOL, TYPE_CINT8:
+		return 1
+	case TYPE_UINT16, TYPE_INT16, TYPE_CINT16:
+		return 2
+	case TYPE_CINT24:
+		return 3
+	case TYPE_UINT32, TYPE_INT32, TYPE_FLOAT32, TYPE_CINT32:
+		return 4
+	case TYPE_UINT64, TYPE_INT64, TYPE_FLOAT64, TYPE_CINT64:
+		return 8
+	}
+	return 0
+}
+
+// decode turns a payload, as located by layout, back into a value.
+func decode(typ byte, data []byte) any {
+	switch typ {
+	case TYPE_UINT8:
+		return data[0]
+	case TYPE_UINT16:
+		return binary.LittleEndian.Uint16(data)
+	case TYPE_UINT32:
+		return binary.LittleEndian.Uint32(data)
+	case TYPE_UINT64:
+		return binary.LittleEndian.Uint64(data)
+
+	case TYPE_INT8:
+		return int8(data[0])
+	case TYPE_INT16:
+		return int16(binary.LittleEndian.Uint16(data))
+	case TYPE_INT32:
+		return int32(binary.LittleEndian.Uint32(data))
+	case TYPE_INT64:
+		return int64(binary.LittleEndian.Uint64(data))
+
+	case TYPE_FLOAT32:
+		return math.Float32frombits(binary.LittleEndian.Uint32(data))
+	case TYPE_FLOAT64:
+		return math.Float64frombits(binary.LittleEndian.Uint64(data))
+
+	case TYPE_BOOL:
+		return data[0] == 1
+	case TYPE_STRING:
+		return string(data)
+	case TYPE_BLOB:
+		return bytes.Clone(data)
+	}
+	return decodeCompactInt(typ, data)
+}
+
+func decodeCompactInt(typ byte, data []byte) int64 {
+	switch typ {
+	case TYPE_CINT8:
+		return int64(int8(data[0]))
+	case TYPE_CINT16:
+		return int64(int16(binary.LittleEndian.Uint16(data)))
+	case TYPE_CINT24:
+		// Shift the 24 bits to the top of an int32 and back to sign-extend.
+		v := int32(uint32(data[0])<<8 | uint32(data[1])<<16 | uint32(data[2])<<24)
+		return int64(v >> 8)
+	case TYPE_CINT32:
+		return int64(int32(binary.LittleEndian.Uint32(data)))
+	case TYPE_CINT64:
+		return int64(binary.LittleEndian.Uint64(data))
+	}
+	return int64(typ - TYPE_IMM)
+}
+
+// appendStringHeader appends the type byte and length of a string of n bytes,
+// using the shortest header that fits.
+func appendStringHeader(dst []byte, n int) []byte {
+	switch {
+	case n < 1<<6:
+		return append(dst, TYPE_STR6|byte(n))
+	case n < 1<<14:
+		return append(dst, TYPE_STR14|byte(n>>8), byte(n))
+	default:
+		dst = append(dst, TYPE_STRING)
+		return binary.LittleEndian.AppendUint32(dst, uint32(n))
+	}
+}
+
+// appendCompactInt appends n with the narrowest compact integer encoding.
+func appendCompactInt(dst []byte, n int64) []byte {
+	switch {
+	case n >= 0 && n <= TYPE_IMMMAX-TYPE_IMM:
+		return append(dst, TYPE_IMM+byte(n))
+	case n >= math.MinInt8 && n <= math.MaxInt8:
+		return append(dst, TYPE_CINT8, byte(n))
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		dst = append(dst, TYPE_CINT16)
+		return binary.LittleEndian.AppendUint16(dst, uint16(n))
+	case n >= -1<<23 && n < 1<<23:
+		return append(dst, TYPE_CINT24, byte(n), byte(n>>8), byte(n>>16))
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		dst = append(dst, TYPE_CINT32)
+		return binary.LittleEndian.AppendUint32(dst, uint32(n))
+	default:
+		dst = append(dst, TYPE_CINT64)
+		return binary.LittleEndian.AppendUint64(dst, uint64(n))
+	}
+}
+
+// asInt64 converts any Go integer that fits an int64.
+func asInt64(value any) (int64, bool) {
+	switch v := value.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint:
+		return int64(v), v <= math.MaxInt64
+	case uint64:
+		return int64(v), v <= math.MaxInt64
+	}
+	return 0, false
+}
@@ -0,0 +1,142 @@
+package ziplist
+
+import (
+	"encoding/binary"
+	"iter"
+	"math"
+)
+
+// Entry is a view of one encoded entry. Its payload points into the
+// ziplist's buffer, so it is only valid until the list is next modified.
+type Entry struct {
+	typ  byte
+	data []byte
+}
+
+// Type returns the entry's type tag. Compact strings report TYPE_STRING;
+// immediate integers report TYPE_IMM plus their value.
+func (e Entry) Type() byte {
+	return e.typ
+}
+
+// Value decodes the entry the same way At does.
+func (e Entry) Value() any {
+	return decode(e.typ, e.data)
+}
+
+// Int returns the entry as an int64. It accepts every integer type, and
+// fails for a uint64 above math.MaxInt64.
+func (e Entry) Int() (int64, bool) {
+	switch e.typ {
+	case TYPE_UINT8, TYPE_UINT16, TYPE_UINT32:
+		u, _ := e.Uint()
+		return int64(u), true
+	case TYPE_UINT64:
+		u := binary.LittleEndian.Uint64(e.data)
+		return int64(u), u <= math.MaxInt64
+	case TYPE_INT8:
+		return int64(int8(e.data[0])), true
+	case TYPE_INT16:
+		return int64(int16(binary.LittleEndian.Uint16(e.data))), true
+	case TYPE_INT32:
+		return int64(int32(binary.LittleEndian.Uint32(e.data))), true
+	case TYPE_INT64:
+		return int64(binary.LittleEndian.Uint64(e.data)), true
+	}
+	if e.isCompactInt() {
+		return decodeCompactInt(e.typ, e.data), true
+	}
+	return 0, false
+}
+
+// Uint returns the entry as a uint64. It accepts every integer type, and
+// fails for negative values.
+func (e Entry) Uint() (uint64, bool) {
+	switch e.typ {
+	case TYPE_UINT8:
+		return uint64(e.data[0]), true
+	case TYPE_UINT16:
+		return uint64(binary.LittleEndian.Uint16(e.data)), true
+	case TYPE_UINT32:
+		return uint64(binary.LittleEndian.Uint32(e.data)), true
+	case TYPE_UINT64:
+		return binary.LittleEndian.Uint64(e.data), true
+	}
+	n, ok := e.Int()
+	return uint64(n), ok && n >= 0
+}
+
+// Float returns the entry as a float64. It accepts both float types.
+func (e Entry) Float() (float64, bool) {
+	switch e.typ {
+	case TYPE_FLOAT32:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(e.data))), true
+	case TYPE_FLOAT64:
+		return math.Float64frombits(binary.LittleEndian.Uint64(e.data)), true
+	}
+	return 0, false
+}
+
+func (e Entry) Bool() (bool, bool) {
+	if e.typ != TYPE_BOOL {
+		return false, false
+	}
+	return e.data[0] == 1, true
+}
+
+// Bytes returns the payload of a string or blob entry, without copying it.
+func (e Entry) Bytes() ([]byte, bool) {
+	if e.typ != TYPE_STRING && e.typ != TYPE_BLOB {
+		return nil, false
+	}
+	return e.data, true
+}
+
+func (e Entry) isCompactInt() bool {
+	return (e.typ >= TYPE_CINT8 && e.typ <= TYPE_CINT64) ||
+		(e.typ >= TYPE_IMM && e.typ <= TYPE_IMMMAX)
+}
+
+// entryAt returns the entry at offset and the offset of the one after it.
+func (zl *Ziplist) entryAt(offset int) (Entry, int, error) {
+	typ, dataStart, dataLen, err := zl.layout(offset)
+	if err != nil {
+		return Entry{}, 0, err
+	}
+	end := dataStart + dataLen
+	return Entry{typ: typ, data: zl.bytes[dataStart:end:end]}, end, nil
+}
+
+// All returns the entries from the first to the last, with their index,
+// walking the buffer once. The list must not be modified during the loop.
+func (zl *Ziplist) All() iter.Seq2[int, Entry] {
+	return func(yield func(int, Entry) bool) {
+		offset := headerSize
+		for i := 0; i < zl.Len(); i++ {
+			e, next, err := zl.entryAt(offset)
+			if err != nil || !yield(i, e) {
+				return
+			}
+			offset = next
+		}
+	}
+}
+
+// Backward returns the entries from the last to the first, with their index.
+// The list must not be modified during the loop.
+func (zl *Ziplist) Backward() iter.Seq2[int, Entry] {
+	return func(yield func(int, Entry) bool) {
+		offset := zl.tail()
+		for i := zl.Len() - 1; i >= 0; i-- {
+			e, _, err := zl.entryAt(offset)
+			if err != nil || !yield(i, e) {
+				return
+			}
+			prev, _, err := zl.prevlen(offset)
+			if err != nil {
+				return
+			}
+			offset -= prev
+		}
+	}
+}
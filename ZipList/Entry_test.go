@@ -0,0 +1,119 @@
+package ziplist
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestAllMatchesAt(t *testing.T) {
+	for _, zl := range seedLists() {
+		next := 0
+		for i, e := range zl.All() {
+			if i != next {
+				t.Fatalf("All yielded index %d, want %d", i, next)
+			}
+			want, _ := zl.At(i)
+			if got := e.Value(); !reflect.DeepEqual(got, want) {
+				t.Fatalf("entry %d: Value() = %#v, At = %#v", i, got, want)
+			}
+			next++
+		}
+		if next != zl.Len() {
+			t.Fatalf("All yielded %d entries of %d", next, zl.Len())
+		}
+	}
+}
+
+func TestAllZeroCopy(t *testing.T) {
+	zl := New(0)
+	for range 100 {
+		zl.Push("payload")
+		zl.Push([]byte("blob"))
+	}
+	// Writing through a view changes the list: it is not a copy.
+	for i, e := range zl.All() {
+		b, ok := e.Bytes()
+		if !ok {
+			t.Fatalf("entry %d: Bytes() failed", i)
+		}
+		b[0] = 'X'
+	}
+	if v, _ := zl.At(0); v != "Xayload" {
+		t.Fatalf("At(0) = %q after writing through the view", v)
+	}
+	if v, _ := zl.At(1); string(v.([]byte)) != "Xlob" {
+		t.Fatalf("At(1) = %q after writing through the view", v)
+	}
+
+	n := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, e := range zl.All() {
+			b, _ := e.Bytes()
+			n += len(b)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("iterating allocates %.0f times", allocs)
+	}
+}
+
+func TestAllStops(t *testing.T) {
+	zl := New(0)
+	for n := range 10 {
+		zl.Push(n)
+	}
+	count := 0
+	for i := range zl.All() {
+		if count++; i == 2 {
+			break
+		}
+	}
+	if count != 3 {
+		t.Fatalf("loop ran %d times", count)
+	}
+}
+
+func TestEntryAccessors(t *testing.T) {
+	zl := New(0)
+	zl.Push(uint64(math.MaxUint64))
+	zl.Push(int8(-3))
+	zl.Push("s")
+	zl.Push(float32(1.5))
+	zl.Push(true)
+	var es []Entry
+	for _, e := range zl.All() {
+		es = append(es, e)
+	}
+
+	if _, ok := es[0].Int(); ok {
+		t.Error("Int() accepted a uint64 above MaxInt64")
+	}
+	if u, ok := es[0].Uint(); !ok || u != math.MaxUint64 {
+		t.Errorf("Uint() = %d, %v", u, ok)
+	}
+	if _, ok := es[1].Uint(); ok {
+		t.Error("Uint() accepted a negative value")
+	}
+	if n, ok := es[1].Int(); !ok || n != -3 {
+		t.Errorf("Int() = %d, %v", n, ok)
+	}
+	if _, ok := es[2].Int(); ok {
+		t.Error("Int() accepted a string")
+	}
+	if _, ok := es[1].Float(); ok {
+		t.Error("Float() accepted an integer")
+	}
+	if f, ok := es[3].Float(); !ok || f != 1.5 {
+		t.Errorf("Float() = %v, %v", f, ok)
+	}
+	if _, ok := es[3].Bytes(); ok {
+		t.Error("Bytes() accepted a float")
+	}
+	if b, ok := es[4].Bool(); !ok || !b {
+		t.Errorf("Bool() = %v, %v", b, ok)
+	}
+	if es[2].Type() != TYPE_STRING || es[4].Type() != TYPE_BOOL {
+		t.Errorf("types %#x, %#x", es[2].Type(), es[4].Type())
+	}
+}
@@ -0,0 +1,297 @@
+package ziplist
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+// Marshal encodes a struct, or a pointer to one, as a ziplist with one entry
+// per exported field:
+//
+//   - integers, floats, bools and strings use their own type;
+//   - []byte is stored as a blob;
+//   - other slices are stored as a uint32 count followed by their elements;
+//   - nested structs are stored as their fields, inline.
+//
+// Fields follow declaration order. The tag `ziplist:"N"` pins a field to
+// position N, and the untagged fields fill the other positions in order;
+// pinning keeps the layout stable when fields are reordered. `ziplist:"-"`
+// skips a field.
+func Marshal(v any) (*Ziplist, error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("ziplist: Marshal of non-struct %T", v)
+	}
+	zl := New(64)
+	if err := marshalValue(zl, rv); err != nil {
+		return nil, err
+	}
+	return zl, nil
+}
+
+// Unmarshal decodes a ziplist written by Marshal into the struct v points
+// to. Integers and floats are converted to the field's type, and an error is
+// returned if a value does not fit, if an entry has the wrong type, or if the
+// number of entries does not match.
+func Unmarshal(zl *Ziplist, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("ziplist: Unmarshal needs a non-nil struct pointer, got %T", v)
+	}
+	d := &decoder{zl: zl, offset: headerSize}
+	if err := d.value(rv.Elem()); err != nil {
+		return err
+	}
+	if d.index != zl.Len() {
+		return fmt.Errorf("ziplist: %d entries left after decoding", zl.Len()-d.index)
+	}
+	return nil
+}
+
+// fields returns the indexes of the encoded fields of t, in layout order.
+// Tagged fields take the position in their tag, and the others fill the
+// free positions in declaration order.
+func fields(t reflect.Type) ([]int, error) {
+	type field struct{ index, pos int }
+	var fs []field
+	taken := map[int]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("ziplist")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		pos := -1
+		if tag != "" {
+			n, err := strconv.Atoi(tag)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("ziplist: bad tag %q on %s.%s", tag, t, f.Name)
+			}
+			if taken[n] {
+				return nil, fmt.Errorf("ziplist: two fields of %s at position %d", t, n)
+			}
+			taken[n] = true
+			pos = n
+		}
+		fs = append(fs, field{i, pos})
+	}
+
+	next := 0
+	for i := range fs {
+		if fs[i].pos >= 0 {
+			continue
+		}
+		for taken[next] {
+			next++
+		}
+		fs[i].pos = next
+		next++
+	}
+
+	slices.SortFunc(fs, func(a, b field) int { return a.pos - b.pos })
+	idx := make([]int, len(fs))
+	for i, f := range fs {
+		idx[i] = f.index
+	}
+	return idx, nil
+}
+
+func marshalValue(zl *Ziplist, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64, reflect.Bool:
+		// Convert named types back to the builtin one Push knows.
+		return zl.Push(v.Convert(basicTypes[v.Kind()]).Interface())
+	case reflect.String:
+		return zl.Push(v.String())
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return zl.Push(bytes.Clone(v.Bytes()))
+		}
+		if err := checkElem(v.Type()); err != nil {
+			return err
+		}
+		if err := zl.Push(uint32(v.Len())); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := marshalValue(zl, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case reflect.Struct:
+		idx, err := fields(v.Type())
+		if err != nil {
+			return err
+		}
+		for _, i := range idx {
+			if err := marshalValue(zl, v.Field(i)); err != nil {
+				return fmt.Errorf("%s: %w", v.Type().Field(i).Name, err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("ziplist: unsupported type %s", v.Type())
+}
+
+// checkElem rejects slices whose elements encode to no entries, such as
+// []struct{}: their length could not be checked against the entries left.
+func checkElem(t reflect.Type) error {
+	n, err := minEntries(t.Elem())
+	if err == nil && n == 0 {
+		err = fmt.Errorf("ziplist: unsupported type %s: elements encode to no entries", t)
+	}
+	return err
+}
+
+// minEntries returns how many entries a value of type t takes at least.
+func minEntries(t reflect.Type) (int, error) {
+	if t.Kind() != reflect.Struct {
+		return 1, nil
+	}
+	idx, err := fields(t)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, i := range idx {
+		m, err := minEntries(t.Field(i).Type)
+		if err != nil {
+			return 0, err
+		}
+		n += m
+	}
+	return n, nil
+}
+
+var basicTypes = map[reflect.Kind]reflect.Type{
+	reflect.Int:     reflect.TypeFor[int](),
+	reflect.Int8:    reflect.TypeFor[int8](),
+	reflect.Int16:   reflect.TypeFor[int16](),
+	reflect.Int32:   reflect.TypeFor[int32](),
+	reflect.Int64:   reflect.TypeFor[int64](),
+	reflect.Uint:    reflect.TypeFor[uint](),
+	reflect.Uint8:   reflect.TypeFor[uint8](),
+	reflect.Uint16:  reflect.TypeFor[uint16](),
+	reflect.Uint32:  reflect.TypeFor[uint32](),
+	reflect.Uint64:  reflect.TypeFor[uint64](),
+	reflect.Float32: reflect.TypeFor[float32](),
+	reflect.Float64: reflect.TypeFor[float64](),
+	reflect.Bool:    reflect.TypeFor[bool](),
+}
+
+// decoder reads the entries of a ziplist one after the other.
+type decoder struct {
+	zl     *Ziplist
+	offset int
+	index  int
+}
+
+func (d *decoder) next() (Entry, error) {
+	if d.index >= d.zl.Len() {
+		return Entry{}, fmt.Errorf("ziplist: too few entries")
+	}
+	e, next, err := d.zl.entryAt(d.offset)
+	if err != nil {
+		return Entry{}, err
+	}
+	d.offset = next
+	d.index++
+	return e, nil
+}
+
+func (d *decoder) value(v reflect.Value) error {
+	if v.Kind() == reflect.Struct {
+		idx, err := fields(v.Type())
+		if err != nil {
+			return err
+		}
+		for _, i := range idx {
+			if err := d.value(v.Field(i)); err != nil {
+				return fmt.Errorf("%s: %w", v.Type().Field(i).Name, err)
+			}
+		}
+		return nil
+	}
+
+	at := d.index
+	e, err := d.next()
+	if err != nil {
+		return err
+	}
+	mismatch := func() error {
+		return &TypeError{at, e.Type(), v.Type().String()}
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := e.Int()
+		if !ok || v.OverflowInt(n) {
+			return mismatch()
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, ok := e.Uint()
+		if !ok || v.OverflowUint(n) {
+			return mismatch()
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, ok := e.Float()
+		if !ok || v.OverflowFloat(f) {
+			return mismatch()
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		b, ok := e.Bool()
+		if !ok {
+			return mismatch()
+		}
+		v.SetBool(b)
+	case reflect.String:
+		b, ok := e.Bytes()
+		if !ok {
+			return mismatch()
+		}
+		v.SetString(string(b))
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b, ok := e.Bytes()
+			if !ok {
+				return mismatch()
+			}
+			v.SetBytes(bytes.Clone(b))
+			return nil
+		}
+		if err := checkElem(v.Type()); err != nil {
+			return err
+		}
+		n, ok := e.Uint()
+		// Every element takes at least one entry, which bounds the count.
+		if !ok || n > uint64(d.zl.Len()-d.index) {
+			return fmt.Errorf("ziplist: bad slice count at entry %d", at)
+		}
+		s := reflect.MakeSlice(v.Type(), int(n), int(n))
+		for i := 0; i < int(n); i++ {
+			if err := d.value(s.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(s)
+
+	default:
+		return fmt.Errorf("ziplist: unsupported type %s", v.Type())
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package ziplist
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type level int8
+
+type point struct {
+	X, Y float32
+}
+
+type record struct {
+	Name   string
+	Age    uint16
+	Tags   []string
+	Lvl    level
+	hidden int
+	Skip   int `ziplist:"-"`
+	Raw    []byte
+	Origin point
+	Path   []point
+	Matrix [][]int
+	First  bool  `ziplist:"0"`
+	Third  int64 `ziplist:"2"`
+}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	in := record{
+		Name: "ada", Age: 36, Tags: []string{"a", "b"}, Lvl: -3,
+		hidden: 1, Skip: 4, Raw: []byte{9, 0},
+		Origin: point{1, 2}, Path: []point{{3, 4}, {5, 6}},
+		Matrix: [][]int{{1}, {}, {2, 3}},
+		First:  true, Third: -7,
+	}
+	zl, err := Marshal(&in)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Pinned fields come first, the others fill the free positions in order.
+	want := []any{true, "ada", int64(-7), uint16(36), uint32(2), "a", "b", int8(-3), []byte{9, 0}}
+	for i, w := range want {
+		if got, _ := zl.At(i); !reflect.DeepEqual(got, w) {
+			t.Fatalf("entry %d = %#v, want %#v", i, got, w)
+		}
+	}
+
+	var out record
+	if err := Unmarshal(zl, &out); err != nil {
+		t.Fatal(err)
+	}
+	in.hidden, in.Skip = 0, 0
+	if !reflect.DeepEqual(out, in) {
+		t.Fatalf("round trip:\n got %+v\nwant %+v", out, in)
+	}
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	mustMarshal := func(v any) *Ziplist {
+		zl, err := Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return zl
+	}
+	isTypeError := func(err error) bool {
+		var te *TypeError
+		return errors.As(err, &te)
+	}
+
+	var i8 struct{ A int8 }
+	if err := Unmarshal(mustMarshal(struct{ A int }{300}), &i8); !isTypeError(err) {
+		t.Errorf("int overflow: %v", err)
+	}
+	var f32 struct{ F float32 }
+	if err := Unmarshal(mustMarshal(struct{ F float64 }{1e300}), &f32); !isTypeError(err) {
+		t.Errorf("float overflow: %v", err)
+	}
+	var u struct{ U uint }
+	if err := Unmarshal(mustMarshal(struct{ I int }{-1}), &u); !isTypeError(err) {
+		t.Errorf("negative into uint: %v", err)
+	}
+	var s struct{ S string }
+	if err := Unmarshal(mustMarshal(struct{ B bool }{true}), &s); !isTypeError(err) {
+		t.Errorf("bool into string: %v", err)
+	}
+	var sl struct{ S []int }
+	if err := Unmarshal(mustMarshal(struct{ N uint32 }{1 << 30}), &sl); err == nil {
+		t.Error("huge slice count accepted")
+	}
+	var short struct{ A, B int }
+	if err := Unmarshal(mustMarshal(struct{ A int }{1}), &short); err == nil {
+		t.Error("too few entries accepted")
+	}
+	if err := Unmarshal(mustMarshal(struct{ A, B int }{1, 2}), &i8); err == nil {
+		t.Error("trailing entries accepted")
+	}
+}
+
+func TestMarshalRejects(t *testing.T) {
+	cases := map[string]any{
+		"non-struct": 42,
+		"map field":  struct{ M map[int]int }{},
+		"duplicate tag": struct {
+			A, B int `ziplist:"1"`
+		}{},
+		"bad tag": struct {
+			A int `ziplist:"x"`
+		}{},
+		"empty elements":    struct{ X []struct{} }{X: make([]struct{}, 3)},
+		"no-entry elements": struct{ X []struct{ y int } }{X: make([]struct{ y int }, 1)},
+	}
+	for name, v := range cases {
+		if _, err := Marshal(v); err == nil {
+			t.Errorf("%s: Marshal succeeded", name)
+		}
+	}
+
+	// Unmarshal refuses the same types, whatever the list holds.
+	zl := New(0)
+	zl.Push(uint32(0))
+	var empty struct{ X []struct{} }
+	if err := Unmarshal(zl, &empty); err == nil {
+		t.Error("Unmarshal into []struct{} succeeded")
+	}
+}
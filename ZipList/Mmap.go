@@ -0,0 +1,252 @@
+//go:build linux
+
+package ziplist
+
+import (
+	"fmt"
+	"iter"
+	"os"
+	"syscall"
+)
+
+// File is an append-only ziplist whose buffer is a memory-mapped file.
+//
+// Push writes the new entries and the end marker first, syncs them, and only
+// then updates the header and syncs again, so the header never describes
+// entries that are not on disk. OpenFile recovers a file left by a crash: it
+// keeps the entries counted in the header, plus any later ones fully written
+// up to an end marker, and drops a torn last append.
+type File struct {
+	f    *os.File
+	mmap []byte
+	zl   Ziplist // its buffer is the used part of mmap
+}
+
+const fileMinSize = 4096
+
+// OpenFile opens the ziplist stored at path, creating it if needed.
+func OpenFile(path string) (*File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	zf := &File{f: f}
+	if err := zf.open(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return zf, nil
+}
+
+func (zf *File) open() error {
+	info, err := zf.f.Stat()
+	if err != nil {
+		return err
+	}
+	size := info.Size()
+
+	if size == 0 {
+		if err := zf.remap(fileMinSize); err != nil {
+			return err
+		}
+		// Build the empty header in the mapping itself.
+		zf.zl.bytes = zf.mmap[:0]
+		zf.zl.resetHeader()
+		return zf.f.Sync()
+	}
+
+	if size < headerSize+1 {
+		return fmt.Errorf("%w: %d bytes is shorter than the header", ErrCorrupt, size)
+	}
+	if err := zf.remap(int(size)); err != nil {
+		return err
+	}
+	b := zf.mmap
+	if b[0] != TYPE_TOTAL_BYTE || b[countOffset-1] != TYPE_LEN || b[tailOffset-1] != TYPE_TAIL {
+		return fmt.Errorf("%w: bad header markers", ErrCorrupt)
+	}
+	return zf.recover()
+}
+
+// recover finds the last complete entry and rewrites the end marker and the
+// header after it.
+func (zf *File) recover() error {
+	scan := &Ziplist{bytes: zf.mmap}
+	committed := scan.Len()
+
+	// Walk the chain of entries while each one decodes and its previous
+	// length matches, which stops at the zeros of unused space too.
+	type pos struct{ offset, next int }
+	var entries []pos
+	offset, prevSize, ended := headerSize, 0, false
+	for offset < len(zf.mmap) {
+		if zf.mmap[offset] == TYPE_END {
+			ended = true
+			break
+		}
+		prev, _, err := scan.prevlen(offset)
+		if err != nil || prev != prevSize {
+			break
+		}
+		_, next, err := scan.entryAt(offset)
+		if err != nil {
+			break
+		}
+		entries = append(entries, pos{offset, next})
+		prevSize, offset = next-offset, next
+	}
+
+	// Entries past the header's count are only kept if they run up to an
+	// end marker: otherwise the last append was torn.
+	keep := len(entries)
+	if !ended {
+		keep = min(keep, committed)
+	}
+
+	end, tail := headerSize, headerSize
+	if keep > 0 {
+		end, tail = entries[keep-1].next, entries[keep-1].offset
+	}
+	if end == len(zf.mmap) {
+		// The file was cut right after a whole entry: make room for the end
+		// marker.
+		if err := zf.remap(end + 1); err != nil {
+			return err
+		}
+	}
+	zf.mmap[end] = TYPE_END
+	// Clear what a torn append left behind: the next recovery relies on the
+	// unused space being zero.
+	clear(zf.mmap[end+1:])
+	zf.zl.bytes = zf.mmap[: end+1 : end+1]
+	zf.zl.setTail(tail)
+	zf.zl.setHeader(keep)
+	return zf.f.Sync()
+}
+
+// remap grows the file to size bytes and maps all of it, moving the list's
+// buffer to the new mapping.
+func (zf *File) remap(size int) error {
+	if zf.mmap != nil {
+		if err := syscall.Munmap(zf.mmap); err != nil {
+			return err
+		}
+		zf.mmap = nil
+	}
+	if err := zf.f.Truncate(int64(size)); err != nil {
+		return err
+	}
+	b, err := syscall.Mmap(int(zf.f.Fd()), 0, size, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return err
+	}
+	zf.mmap = b
+	if zf.zl.bytes != nil {
+		n := len(zf.zl.bytes)
+		zf.zl.bytes = b[:n:n]
+	}
+	return nil
+}
+
+// Push appends values as one durable batch: when it returns nil, they are on
+// disk. Values are encoded as by Ziplist.Push.
+func (zf *File) Push(values ...any) error {
+	end := len(zf.zl.bytes) - 1
+	count, tail := zf.zl.Len(), zf.zl.tail()
+
+	var buf []byte
+	for _, v := range values {
+		start := len(buf)
+		prevSize := 0
+		if count > 0 {
+			prevSize = end + start - tail
+		}
+		buf = appendPrevlen(buf, prevSize)
+		var err error
+		if buf, err = appendValue(buf, v, false); err != nil {
+			return err
+		}
+		tail = end + start
+		count++
+	}
+	if len(buf) == 0 {
+		return nil
+	}
+
+	need := end + len(buf) + 1
+	if need > len(zf.mmap) {
+		size := len(zf.mmap)
+		for size < need {
+			size *= 2
+		}
+		if err := zf.remap(size); err != nil {
+			return err
+		}
+	}
+
+	// Entries and end marker first...
+	copy(zf.mmap[end:], buf)
+	zf.mmap[need-1] = TYPE_END
+	if err := zf.f.Sync(); err != nil {
+		return err
+	}
+
+	// ...then the header that makes them count.
+	zf.zl.bytes = zf.mmap[:need:need]
+	zf.zl.setTail(tail)
+	zf.zl.setHeader(count)
+	return zf.f.Sync()
+}
+
+// Close syncs the file, trims it to the used size and unmaps it.
+func (zf *File) Close() error {
+	size := len(zf.zl.bytes)
+	err := syscall.Munmap(zf.mmap)
+	zf.mmap, zf.zl.bytes = nil, nil
+	if err == nil {
+		err = zf.f.Truncate(int64(size))
+	}
+	if err == nil {
+		err = zf.f.Sync()
+	}
+	if cerr := zf.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// Len returns the number of entries.
+func (zf *File) Len() int {
+	return zf.zl.Len()
+}
+
+// Size returns the used length of the file in bytes.
+func (zf *File) Size() int {
+	return zf.zl.Size()
+}
+
+// At returns the value at the given index.
+func (zf *File) At(index int) (any, error) {
+	return zf.zl.At(index)
+}
+
+// Last returns the value of the last entry.
+func (zf *File) Last() (any, error) {
+	return zf.zl.Last()
+}
+
+// All returns the entries from the first to the last. Entries are views
+// into the mapping, valid until the next Push or Close.
+func (zf *File) All() iter.Seq2[int, Entry] {
+	return zf.zl.All()
+}
+
+// Backward returns the entries from the last to the first.
+func (zf *File) Backward() iter.Seq2[int, Entry] {
+	return zf.zl.Backward()
+}
+
+// Bytes returns a copy of the encoded list, which FromBytes accepts.
+func (zf *File) Bytes() []byte {
+	return zf.zl.Bytes()
+}
@@ -0,0 +1,222 @@
+//go:build linux
+
+package ziplist
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+)
+
+func checkFile(t *testing.T, zf *File, want []any) {
+	t.Helper()
+	var got []any
+	for _, e := range zf.All() {
+		got = append(got, e.Value())
+	}
+	if zf.Len() != len(want) || !reflect.DeepEqual(got, want) {
+		t.Fatalf("file holds %d entries %.60v, want %d %.60v", zf.Len(), got, len(want), want)
+	}
+	// What is mapped is a valid list.
+	if _, err := FromBytes(zf.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func reopen(t *testing.T, path string) *File {
+	t.Helper()
+	zf, err := OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zf
+}
+
+// writeFile creates a file holding values, closed and trimmed to its used
+// size, and returns its path and content.
+func writeFile(t *testing.T, values ...any) (string, []byte) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "list.zl")
+	zf := reopen(t, path)
+	if err := zf.Push(values...); err != nil {
+		t.Fatal(err)
+	}
+	if err := zf.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, data
+}
+
+// lastEntrySize returns the encoded size of the last entry of data.
+func lastEntrySize(t *testing.T, data []byte) int {
+	t.Helper()
+	zl, err := FromBytes(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(data) - 1 - zl.tail()
+}
+
+func TestFileReopen(t *testing.T) {
+	big := strings.Repeat("x", 3000)
+	want := []any{"a", int64(1)}
+	path, data := writeFile(t, want...)
+	// "a" takes 3 bytes, int64(1) 10, then the end marker.
+	if len(data) != headerSize+3+10+1 {
+		t.Fatalf("Close left %d bytes", len(data))
+	}
+
+	zf := reopen(t, path)
+	checkFile(t, zf, want)
+	// Enough to grow the mapping past its first 4 KiB, twice.
+	for range 3 {
+		if err := zf.Push(big, []byte{1, 2}); err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, big, []byte{1, 2})
+	}
+	checkFile(t, zf, want)
+	if last, _ := zf.Last(); !reflect.DeepEqual(last, []byte{1, 2}) {
+		t.Fatalf("Last() = %v", last)
+	}
+	if err := zf.Push(struct{}{}); err == nil {
+		t.Fatal("Push of an unsupported type succeeded")
+	}
+	zf.Close()
+
+	zf = reopen(t, path)
+	defer zf.Close()
+	checkFile(t, zf, want)
+	if info, _ := os.Stat(path); info.Size() != int64(zf.Size()) {
+		t.Fatalf("file is %d bytes, list %d", info.Size(), zf.Size())
+	}
+}
+
+func TestFileRecoversTruncated(t *testing.T) {
+	want := []any{"first", strings.Repeat("y", 300), int64(7)}
+	path, data := writeFile(t, want...)
+	last := lastEntrySize(t, data)
+
+	// Cut off the end marker, then more and more of the last entry: the
+	// header counts that entry, but once it is incomplete it is dropped.
+	for cut := 1; cut <= last+1; cut++ {
+		os.WriteFile(path, data[:len(data)-cut], 0o644)
+		zf := reopen(t, path)
+		wantKept := want[:2]
+		if cut == 1 {
+			// Only the end marker is missing: every entry is whole.
+			wantKept = want
+		}
+		checkFile(t, zf, wantKept)
+		// The recovered file takes appends again.
+		if err := zf.Push("after"); err != nil {
+			t.Fatal(err)
+		}
+		zf.Close()
+		zf = reopen(t, path)
+		checkFile(t, zf, append(wantKept[:len(wantKept):len(wantKept)], "after"))
+		zf.Close()
+	}
+
+	for _, size := range []int{1, headerSize} {
+		os.WriteFile(path, data[:size], 0o644)
+		if _, err := OpenFile(path); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%d bytes: err = %v, want ErrCorrupt", size, err)
+		}
+	}
+	bad := append([]byte(nil), data...)
+	bad[countOffset-1] = 0
+	os.WriteFile(path, bad, 0o644)
+	if _, err := OpenFile(path); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("bad marker: err = %v, want ErrCorrupt", err)
+	}
+}
+
+func TestFileRecoversTornTail(t *testing.T) {
+	want := []any{"a", strings.Repeat("z", 260)}
+	path, data := writeFile(t, want...)
+	prev := lastEntrySize(t, data)
+	end := len(data) - 1
+
+	next := entryBytes(t, prev, "next")
+	after := entryBytes(t, len(next), int64(42))
+
+	// A crash after the data but before the header: the entries run up to
+	// an end marker, so they were fully written and are kept.
+	whole := append(append(append(data[:end:end], next...), after...), TYPE_END)
+	os.WriteFile(path, whole, 0o644)
+	zf := reopen(t, path)
+	checkFile(t, zf, append(want[:2:2], "next", int64(42)))
+	zf.Close()
+
+	// A crash in the middle of the data: no end marker, so everything past
+	// the header's count is dropped, even the entries that look whole.
+	torn := append(append(data[:end:end], next...), after[:3]...)
+	for _, tail := range [][]byte{torn, append(torn, make([]byte, 100)...)} {
+		os.WriteFile(path, tail, 0o644)
+		zf := reopen(t, path)
+		checkFile(t, zf, want)
+		zf.Close()
+	}
+}
+
+// crash drops zf without the trimming Close does, as a crash would.
+func crash(t *testing.T, zf *File) {
+	t.Helper()
+	if err := syscall.Munmap(zf.mmap); err != nil {
+		t.Fatal(err)
+	}
+	zf.f.Close()
+}
+
+func TestFileRecoversTwoTornAppends(t *testing.T) {
+	want := []any{"a", "b"}
+	path, data := writeFile(t, want...)
+	end := len(data) - 1
+	next := entryBytes(t, lastEntrySize(t, data), "next")
+	after := entryBytes(t, len(next), int64(42))
+
+	// The first append only reached the disk in part: its second entry and
+	// end marker did, its first entry did not.
+	torn := append(append(data[:end:end], make([]byte, len(next))...), after...)
+	torn = append(torn, TYPE_END)
+	os.WriteFile(path, torn, 0o644)
+	zf := reopen(t, path)
+	checkFile(t, zf, want)
+	crash(t, zf)
+	left, _ := os.ReadFile(path)
+	if i := slices.IndexFunc(left[end+1:], func(b byte) bool { return b != 0 }); i >= 0 {
+		t.Fatalf("byte %d past the end marker is %#x after recovery", end+1+i, left[end+1+i])
+	}
+
+	// The second append only wrote its first entry. Had the first one's
+	// leftovers stayed, they would now complete it up to an end marker.
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteAt(next, int64(end))
+	f.Close()
+	zf = reopen(t, path)
+	defer zf.Close()
+	checkFile(t, zf, want)
+}
+
+// entryBytes encodes value as an entry following one of prevSize bytes.
+func entryBytes(t *testing.T, prevSize int, value any) []byte {
+	t.Helper()
+	b, err := appendValue(appendPrevlen(nil, prevSize), value, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
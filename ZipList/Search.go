@@ -0,0 +1,146 @@
+package ziplist
+
+import (
+	"bytes"
+	"iter"
+	"math"
+)
+
+// SetNumericMatch chooses how Index, Contains and Count compare numbers.
+// By default an entry matches only if it has the same type and value, so
+// uint8(5) and int32(5) differ. In numeric mode every integer and float
+// entry is compared by value: uint8(5), int32(5) and 5.0 are all equal.
+func (zl *Ziplist) SetNumericMatch(on bool) {
+	zl.numeric = on
+}
+
+// Index returns the index of the first entry equal to value, or -1.
+func (zl *Ziplist) Index(value any) int {
+	for i := range zl.matches(value) {
+		return i
+	}
+	return -1
+}
+
+// Contains reports whether some entry is equal to value.
+func (zl *Ziplist) Contains(value any) bool {
+	return zl.Index(value) >= 0
+}
+
+// Count returns how many entries are equal to value.
+func (zl *Ziplist) Count(value any) int {
+	n := 0
+	for range zl.matches(value) {
+		n++
+	}
+	return n
+}
+
+// Find returns the indexes of the entries for which pred is true.
+func (zl *Ziplist) Find(pred func(Entry) bool) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i, e := range zl.All() {
+			if pred(e) && !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// matches returns the indexes of the entries equal to value. value is
+// encoded once, the way Push would store it, and compared with the encoded
+// entries, so equal values match without being decoded.
+func (zl *Ziplist) matches(value any) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		needle, err := appendValue(nil, value, zl.compact)
+		if err != nil {
+			return
+		}
+		numeric := zl.numericMatcher(value)
+
+		offset := headerSize
+		for i := 0; i < zl.Len(); i++ {
+			_, field, err := zl.prevlen(offset)
+			if err != nil {
+				return
+			}
+			e, next, err := zl.entryAt(offset)
+			if err != nil {
+				return
+			}
+			if bytes.Equal(zl.bytes[offset+field:next], needle) || (numeric != nil && numeric(e)) {
+				if !yield(i) {
+					return
+				}
+			}
+			offset = next
+		}
+	}
+}
+
+// numericMatcher returns a test for entries numerically equal to value, or
+// nil if numeric mode is off or value is not a number. Integers and floats
+// are compared exactly, never through a rounding conversion: 1<<53 + 1 does
+// not match the float 1<<53.
+func (zl *Ziplist) numericMatcher(value any) func(Entry) bool {
+	if !zl.numeric {
+		return nil
+	}
+	switch v := value.(type) {
+	case float32:
+		return matchFloat(float64(v))
+	case float64:
+		return matchFloat(v)
+	case uint:
+		return matchUint(uint64(v))
+	case uint64:
+		return matchUint(v)
+	}
+	if n, ok := asInt64(value); ok {
+		return matchInt(n)
+	}
+	return nil
+}
+
+func matchFloat(f float64) func(Entry) bool {
+	return func(e Entry) bool {
+		if ef, ok := e.Float(); ok {
+			return ef == f
+		}
+		if en, ok := e.Int(); ok {
+			return floatIsInt(f, en)
+		}
+		eu, ok := e.Uint()
+		return ok && floatIsUint(f, eu)
+	}
+}
+
+func matchInt(n int64) func(Entry) bool {
+	return func(e Entry) bool {
+		if en, ok := e.Int(); ok {
+			return en == n
+		}
+		ef, ok := e.Float()
+		return ok && floatIsInt(ef, n)
+	}
+}
+
+func matchUint(u uint64) func(Entry) bool {
+	return func(e Entry) bool {
+		if eu, ok := e.Uint(); ok {
+			return eu == u
+		}
+		ef, ok := e.Float()
+		return ok && floatIsUint(ef, u)
+	}
+}
+
+// floatIsInt reports whether f is exactly the integer n.
+func floatIsInt(f float64, n int64) bool {
+	return f == math.Trunc(f) && f >= math.MinInt64 && f < 1<<63 && int64(f) == n
+}
+
+// floatIsUint reports whether f is exactly the integer u.
+func floatIsUint(f float64, u uint64) bool {
+	return f == math.Trunc(f) && f >= 0 && f < 1<<64 && uint64(f) == u
+}
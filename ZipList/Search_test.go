@@ -0,0 +1,136 @@
+package ziplist
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// searchValues mixes numbers that are equal across types, numbers that only
+// differ beyond float64 precision, and non-numbers that look alike.
+var searchValues = []any{
+	uint8(5), int32(5), int64(5), 5, uint(5), 5.0, float32(5),
+	int8(-5), -5.0,
+	uint64(math.MaxUint64), float64(math.MaxUint64),
+	int64(1<<53 + 1), float64(1 << 53), int64(1 << 53),
+	2.5, float32(2.5),
+	"5", []byte("5"), true, false, "",
+}
+
+// stored returns v as At reads it back from zl.
+func stored(zl *Ziplist, v any) any {
+	tmp := New(0)
+	tmp.compact = zl.compact
+	tmp.Push(v)
+	got, _ := tmp.At(0)
+	return got
+}
+
+// exactNumber returns v as an exact rational, or nil if v is not a number.
+func exactNumber(v any) *big.Rat {
+	switch n := v.(type) {
+	case float32:
+		return new(big.Rat).SetFloat64(float64(n))
+	case float64:
+		return new(big.Rat).SetFloat64(n)
+	case uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(n))
+	case uint:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(uint64(n)))
+	}
+	if n, ok := asInt64(v); ok {
+		return new(big.Rat).SetInt64(n)
+	}
+	return nil
+}
+
+// naiveMatches decodes every entry and compares it with value: by type and
+// value, or numerically in numeric mode.
+func naiveMatches(zl *Ziplist, value any, numeric bool) []int {
+	want := stored(zl, value)
+	var idx []int
+	for i := range zl.Len() {
+		got, _ := zl.At(i)
+		match := reflect.DeepEqual(got, want)
+		if a, b := exactNumber(got), exactNumber(value); numeric && a != nil && b != nil {
+			match = a.Cmp(b) == 0
+		}
+		if match {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+func TestSearchAgainstDecoding(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, compact := range []bool{false, true} {
+		for _, numeric := range []bool{false, true} {
+			zl := New(0)
+			if compact {
+				zl = NewCompact(0)
+			}
+			zl.SetNumericMatch(numeric)
+			for range 200 {
+				zl.Push(searchValues[r.Intn(len(searchValues))])
+			}
+
+			for _, v := range searchValues {
+				want := naiveMatches(zl, v, numeric)
+				first := -1
+				if len(want) > 0 {
+					first = want[0]
+				}
+				if got := zl.Index(v); got != first {
+					t.Fatalf("compact=%v numeric=%v: Index(%T %v) = %d, want %d", compact, numeric, v, v, got, first)
+				}
+				if got := zl.Count(v); got != len(want) {
+					t.Fatalf("compact=%v numeric=%v: Count(%T %v) = %d, want %d", compact, numeric, v, v, got, len(want))
+				}
+				if zl.Contains(v) != (len(want) > 0) {
+					t.Fatalf("compact=%v numeric=%v: Contains(%T %v) = %v", compact, numeric, v, v, len(want) == 0)
+				}
+			}
+		}
+	}
+}
+
+func TestSearchExamples(t *testing.T) {
+	zl := New(0)
+	for _, v := range []any{uint8(5), int32(5), 5.0, "5"} {
+		zl.Push(v)
+	}
+	if zl.Count(int32(5)) != 1 || zl.Index(5.0) != 2 || zl.Contains(int64(5)) {
+		t.Error("exact mode matched across types")
+	}
+	zl.SetNumericMatch(true)
+	if zl.Count(int64(5)) != 3 || zl.Index(float32(5)) != 0 || zl.Count("5") != 1 {
+		t.Error("numeric mode")
+	}
+	if zl.Contains(struct{}{}) || zl.Index(struct{}{}) != -1 {
+		t.Error("an unsupported type matched")
+	}
+}
+
+func TestFind(t *testing.T) {
+	zl := New(0)
+	for n := range 10 {
+		zl.Push(n)
+	}
+	even := func(e Entry) bool {
+		n, _ := e.Int()
+		return n%2 == 0
+	}
+	if got := slices.Collect(zl.Find(even)); !slices.Equal(got, []int{0, 2, 4, 6, 8}) {
+		t.Fatalf("Find = %v", got)
+	}
+	for i := range zl.Find(even) {
+		if i > 0 {
+			t.Fatal("Find did not stop")
+		}
+		break
+	}
+}
@@ -0,0 +1,78 @@
+package ziplist
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrCorrupt is returned by FromBytes for a buffer that is not a valid
+// ziplist.
+var ErrCorrupt = errors.New("ziplist: corrupt buffer")
+
+// Bytes returns a copy of the encoded list, suitable for FromBytes.
+func (zl *Ziplist) Bytes() []byte {
+	return slices.Clone(zl.bytes)
+}
+
+// FromBytes loads a list encoded by Bytes. The whole buffer is checked before
+// it is used: header markers, total length, every entry's bounds and previous
+// length, the entry count, the tail offset and the end marker. data is copied.
+//
+// The returned list is not compact: values pushed to it keep their exact
+// type, while compact entries already in data are read as usual.
+func FromBytes(data []byte) (*Ziplist, error) {
+	zl := &Ziplist{bytes: slices.Clone(data)}
+	if err := zl.validate(); err != nil {
+		return nil, err
+	}
+	return zl, nil
+}
+
+func (zl *Ziplist) validate() error {
+	b := zl.bytes
+	if len(b) < headerSize+1 {
+		return fmt.Errorf("%w: %d bytes is shorter than the header", ErrCorrupt, len(b))
+	}
+	if b[0] != TYPE_TOTAL_BYTE || b[countOffset-1] != TYPE_LEN || b[tailOffset-1] != TYPE_TAIL {
+		return fmt.Errorf("%w: bad header markers", ErrCorrupt)
+	}
+	if total := binary.LittleEndian.Uint32(b[totalOffset : totalOffset+4]); int64(total) != int64(len(b)) {
+		return fmt.Errorf("%w: header says %d bytes, got %d", ErrCorrupt, total, len(b))
+	}
+
+	// Every entry takes at least 2 bytes, which bounds the loop below even
+	// when the count is garbage.
+	count := zl.Len()
+	if count > (len(b)-headerSize-1)/2 {
+		return fmt.Errorf("%w: %d entries cannot fit in %d bytes", ErrCorrupt, count, len(b))
+	}
+
+	offset, last, prevSize := headerSize, headerSize, 0
+	for i := 0; i < count; i++ {
+		if offset >= len(b)-1 || b[offset] == TYPE_END {
+			return fmt.Errorf("%w: end marker after %d of %d entries", ErrCorrupt, i, count)
+		}
+		prev, _, err := zl.prevlen(offset)
+		if err != nil {
+			return fmt.Errorf("%w: entry %d: %v", ErrCorrupt, i, err)
+		}
+		if prev != prevSize {
+			return fmt.Errorf("%w: entry %d: previous length %d, want %d", ErrCorrupt, i, prev, prevSize)
+		}
+		_, next, err := zl.entryAt(offset)
+		if err != nil {
+			return fmt.Errorf("%w: entry %d: %v", ErrCorrupt, i, err)
+		}
+		last, prevSize, offset = offset, next-offset, next
+	}
+
+	if offset != len(b)-1 || b[offset] != TYPE_END {
+		return fmt.Errorf("%w: missing end marker after %d entries", ErrCorrupt, count)
+	}
+	if zl.tail() != last {
+		return fmt.Errorf("%w: tail offset %d, want %d", ErrCorrupt, zl.tail(), last)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package ziplist
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFromBytesRoundTrip(t *testing.T) {
+	for _, zl := range seedLists() {
+		loaded, err := FromBytes(zl.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(loaded.Bytes(), zl.Bytes()) {
+			t.Fatal("Bytes changed after FromBytes")
+		}
+		for i := range zl.Len() {
+			want, _ := zl.At(i)
+			got, err := loaded.At(i)
+			if err != nil || !reflect.DeepEqual(got, want) {
+				t.Fatalf("At(%d) = %v, %v; want %v", i, got, err, want)
+			}
+		}
+	}
+}
+
+func TestFromBytesRejects(t *testing.T) {
+	valid := seedLists()[1].Bytes()
+	cases := map[string][]byte{
+		"empty":        nil,
+		"short":        valid[:headerSize],
+		"truncated":    valid[:len(valid)-1],
+		"no end":       append(bytes.Clone(valid[:len(valid)-1]), 0),
+		"trailing":     append(bytes.Clone(valid), TYPE_END),
+		"bad marker":   append([]byte{0}, valid[1:]...),
+		"bad count":    withUint32(valid, countOffset, 3),
+		"bad total":    withUint32(valid, totalOffset, uint32(len(valid)+1)),
+		"bad tail":     withUint32(valid, tailOffset, headerSize),
+		"bad prevlen":  withByte(valid, headerSize, 5),
+		"huge count":   withUint32(valid, countOffset, math.MaxUint32),
+		"unknown type": withByte(valid, headerSize+1, 0xfd),
+	}
+	for name, data := range cases {
+		if _, err := FromBytes(data); !errors.Is(err, ErrCorrupt) {
+			t.Errorf("%s: got %v, want ErrCorrupt", name, err)
+		}
+	}
+}
+
+func withUint32(b []byte, at int, v uint32) []byte {
+	b = bytes.Clone(b)
+	b[at], b[at+1], b[at+2], b[at+3] = byte(v), byte(v>>8), byte(v>>16), byte(v>>24)
+	return b
+}
+
+func withByte(b []byte, at int, v byte) []byte {
+	b = bytes.Clone(b)
+	b[at] = v
+	return b
+}
+
+func FuzzFromBytes(f *testing.F) {
+	for _, zl := range seedLists() {
+		f.Add(zl.Bytes())
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		zl, err := FromBytes(data)
+		if err != nil {
+			return
+		}
+		if !bytes.Equal(zl.Bytes(), data) {
+			t.Fatal("Bytes does not round-trip")
+		}
+
+		var forward []any
+		for i, e := range zl.All() {
+			if i != len(forward) {
+				t.Fatalf("All yielded index %d, want %d", i, len(forward))
+			}
+			forward = append(forward, e.Value())
+		}
+		if len(forward) != zl.Len() {
+			t.Fatalf("All yielded %d entries, Len is %d", len(forward), zl.Len())
+		}
+		n := 0
+		for i, e := range zl.Backward() {
+			if !reflect.DeepEqual(e.Value(), forward[i]) {
+				t.Fatalf("Backward entry %d differs from All", i)
+			}
+			n++
+		}
+		if n != zl.Len() {
+			t.Fatalf("Backward yielded %d entries, Len is %d", n, zl.Len())
+		}
+		for i, want := range forward {
+			got, err := zl.At(i)
+			if err != nil || !reflect.DeepEqual(got, want) {
+				t.Fatalf("At(%d) = %v, %v; want %v", i, got, err, want)
+			}
+		}
+
+		// A loaded list must stay valid through edits.
+		zl.Push(strings.Repeat("x", 300))
+		zl.PushFront("y")
+		zl.Pop()
+		zl.Remove(0)
+		if _, err := FromBytes(zl.Bytes()); err != nil {
+			t.Fatalf("edited list no longer loads: %v", err)
+		}
+	})
+}
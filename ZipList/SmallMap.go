@@ -0,0 +1,183 @@
+package ziplist
+
+import (
+	"bytes"
+	"iter"
+)
+
+// Default promotion thresholds, the same as Redis' hash-max-ziplist-entries
+// and hash-max-ziplist-value.
+const (
+	DefaultSmallEntries = 128
+	DefaultSmallValue   = 64
+)
+
+// SmallMap is a string-keyed map stored as a ziplist of alternating keys and
+// values while it is small. Lookups scan the list, comparing keys in place.
+// Once it holds more than maxEntries pairs, or a key or value longer than
+// maxValue bytes, it is converted to a Go map for good.
+type SmallMap struct {
+	zl         *Ziplist
+	m          map[string]any
+	maxEntries int
+	maxValue   int
+}
+
+// NewSmallMap returns an empty SmallMap. A limit of 0 selects the default.
+func NewSmallMap(maxEntries, maxValue int) *SmallMap {
+	if maxEntries <= 0 {
+		maxEntries = DefaultSmallEntries
+	}
+	if maxValue <= 0 {
+		maxValue = DefaultSmallValue
+	}
+	return &SmallMap{zl: New(64), maxEntries: maxEntries, maxValue: maxValue}
+}
+
+// Promoted reports whether the map has been converted to a Go map.
+func (sm *SmallMap) Promoted() bool {
+	return sm.m != nil
+}
+
+func (sm *SmallMap) Len() int {
+	if sm.m != nil {
+		return len(sm.m)
+	}
+	return sm.zl.Len() / 2
+}
+
+// index returns the list position of key, or -1.
+func (sm *SmallMap) index(key string) int {
+	for i, e := range sm.zl.All() {
+		if i%2 == 0 {
+			if b, _ := e.Bytes(); string(b) == key {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (sm *SmallMap) Get(key string) (any, bool) {
+	if sm.m != nil {
+		v, ok := sm.m[key]
+		return cloneBytes(v), ok
+	}
+	i := sm.index(key)
+	if i < 0 {
+		return nil, false
+	}
+	v, err := sm.zl.At(i + 1)
+	return v, err == nil
+}
+
+// Set stores value under key. value can be any type a Ziplist accepts, and
+// reads back as At would return it, whether or not the map is promoted.
+func (sm *SmallMap) Set(key string, value any) error {
+	// Check the value encodes before anything changes.
+	if _, err := appendValue(nil, value, false); err != nil {
+		return err
+	}
+	if sm.m != nil {
+		sm.m[key] = normalize(value)
+		return nil
+	}
+
+	i := sm.index(key)
+	if len(key) > sm.maxValue || valueSize(value) > sm.maxValue ||
+		(i < 0 && sm.Len() >= sm.maxEntries) {
+		sm.promote()
+		sm.m[key] = normalize(value)
+		return nil
+	}
+	if i >= 0 {
+		return sm.zl.Set(i+1, value)
+	}
+	if err := sm.zl.Push(key); err != nil {
+		return err
+	}
+	return sm.zl.Push(value)
+}
+
+// Delete removes key. It returns false if key was not stored.
+// A promoted map stays promoted.
+func (sm *SmallMap) Delete(key string) bool {
+	if sm.m != nil {
+		_, ok := sm.m[key]
+		delete(sm.m, key)
+		return ok
+	}
+	i := sm.index(key)
+	if i < 0 {
+		return false
+	}
+	return sm.zl.RemoveRange(i, 2) == nil
+}
+
+// All returns the key-value pairs. The ziplist encoding yields them in
+// insertion order, a promoted map in no particular order.
+func (sm *SmallMap) All() iter.Seq2[string, any] {
+	return func(yield func(string, any) bool) {
+		if sm.m != nil {
+			for k, v := range sm.m {
+				if !yield(k, cloneBytes(v)) {
+					return
+				}
+			}
+			return
+		}
+		var key string
+		for i, e := range sm.zl.All() {
+			if i%2 == 0 {
+				b, _ := e.Bytes()
+				key = string(b)
+			} else if !yield(key, e.Value()) {
+				return
+			}
+		}
+	}
+}
+
+func (sm *SmallMap) promote() {
+	m := make(map[string]any, sm.Len()+1)
+	for k, v := range sm.All() {
+		m[k] = v
+	}
+	sm.m = m
+	sm.zl = nil
+}
+
+// normalize converts value to the form a ziplist entry decodes to: int and
+// uint are stored as 64-bit, and []byte is copied.
+func normalize(value any) any {
+	switch v := value.(type) {
+	case int:
+		return int64(v)
+	case uint:
+		return uint64(v)
+	case []byte:
+		return bytes.Clone(v)
+	}
+	return value
+}
+
+// cloneBytes copies a []byte value, as decoding an entry does, so callers
+// never share the promoted map's storage.
+func cloneBytes(value any) any {
+	if b, ok := value.([]byte); ok {
+		return bytes.Clone(b)
+	}
+	return value
+}
+
+// valueSize returns the payload length of strings and blobs, 0 for the
+// fixed-size types.
+func valueSize(value any) int {
+	switch v := value.(type) {
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	}
+	return 0
+}
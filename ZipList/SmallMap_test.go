@@ -0,0 +1,111 @@
+package ziplist
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// decoded returns value as a ziplist entry reads it back.
+func decoded(t *testing.T, value any) any {
+	t.Helper()
+	zl := New(0)
+	if err := zl.Push(value); err != nil {
+		t.Fatal(err)
+	}
+	v, err := zl.At(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestSmallMapTypesAcrossPromotion(t *testing.T) {
+	sm := NewSmallMap(len(everyType)+1, 1<<20)
+	for i, v := range everyType {
+		if err := sm.Set(fmt.Sprint("before", i), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sm.Promoted() {
+		t.Fatal("promoted too early")
+	}
+	check := func(prefix string) {
+		t.Helper()
+		for i, v := range everyType {
+			got, ok := sm.Get(fmt.Sprint(prefix, i))
+			if want := decoded(t, v); !ok || !reflect.DeepEqual(got, want) {
+				t.Fatalf("%s%d: got %#v (%T), want %#v (%T)", prefix, i, got, got, want, want)
+			}
+		}
+	}
+	check("before")
+
+	// Filling the entry limit promotes the map.
+	for i, v := range everyType {
+		sm.Set(fmt.Sprint("after", i), v)
+	}
+	if !sm.Promoted() {
+		t.Fatal("not promoted")
+	}
+	check("before")
+	check("after")
+
+	if err := sm.Set("bad", struct{}{}); err == nil {
+		t.Fatal("promoted map accepted an unsupported type")
+	}
+}
+
+func TestSmallMapBytesNotAliased(t *testing.T) {
+	for _, promoted := range []bool{false, true} {
+		sm := NewSmallMap(1, 0)
+		if promoted {
+			sm.Set("x", 1)
+		}
+		b := []byte("abc")
+		sm.Set("b", b)
+		if sm.Promoted() != promoted {
+			t.Fatalf("Promoted = %v", sm.Promoted())
+		}
+		b[0] = 'X'
+		got, _ := sm.Get("b")
+		got.([]byte)[1] = 'Y'
+		if again, _ := sm.Get("b"); string(again.([]byte)) != "abc" {
+			t.Fatalf("promoted=%v: stored bytes changed to %q", promoted, again)
+		}
+	}
+}
+
+func TestSmallMapAgainstMap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, limits := range [][2]int{{4, 8}, {0, 0}, {1000, 1000}} {
+		sm := NewSmallMap(limits[0], limits[1])
+		ref := map[string]any{}
+		for step := 0; step < 2000; step++ {
+			k := fmt.Sprint("k", r.Intn(50))
+			if r.Intn(3) == 0 {
+				_, had := ref[k]
+				if sm.Delete(k) != had {
+					t.Fatalf("Delete(%q) != %v", k, had)
+				}
+				delete(ref, k)
+			} else {
+				var v any = int32(r.Intn(9))
+				if r.Intn(2) == 0 {
+					v = strings.Repeat("v", r.Intn(12))
+				}
+				sm.Set(k, v)
+				ref[k] = v
+			}
+			if sm.Len() != len(ref) {
+				t.Fatalf("Len = %d, want %d", sm.Len(), len(ref))
+			}
+			if got := maps.Collect(sm.All()); !reflect.DeepEqual(got, ref) {
+				t.Fatalf("All = %v, want %v", got, ref)
+			}
+		}
+	}
+}
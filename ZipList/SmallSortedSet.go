@@ -0,0 +1,216 @@
+package ziplist
+
+import (
+	"fmt"
+	"iter"
+	"math"
+	"slices"
+
+	rbtree "github.com/JustJ3di/Golletions/RBTree"
+)
+
+// SmallSortedSet is a set of string members ordered by a float64 score, ties
+// broken by member. While small it is a ziplist of member, score pairs kept
+// in order. Once it holds more than maxEntries members, or a member longer
+// than maxValue bytes, it is converted for good to a red-black tree keyed by
+// score plus a map from member to score.
+type SmallSortedSet struct {
+	zl *Ziplist
+
+	tree   *rbtree.RBtree[float64] // score -> sorted []string of members
+	scores map[string]float64
+
+	maxEntries int
+	maxValue   int
+}
+
+// NewSmallSortedSet returns an empty SmallSortedSet. A limit of 0 selects
+// the default.
+func NewSmallSortedSet(maxEntries, maxValue int) *SmallSortedSet {
+	if maxEntries <= 0 {
+		maxEntries = DefaultSmallEntries
+	}
+	if maxValue <= 0 {
+		maxValue = DefaultSmallValue
+	}
+	return &SmallSortedSet{zl: New(64), maxEntries: maxEntries, maxValue: maxValue}
+}
+
+// Promoted reports whether the set has been converted to a tree.
+func (ss *SmallSortedSet) Promoted() bool {
+	return ss.tree != nil
+}
+
+func (ss *SmallSortedSet) Len() int {
+	if ss.tree != nil {
+		return len(ss.scores)
+	}
+	return ss.zl.Len() / 2
+}
+
+// index returns the list position and score of member, or -1.
+func (ss *SmallSortedSet) index(member string) (int, float64) {
+	found := -1
+	for i, e := range ss.zl.All() {
+		if i%2 == 0 {
+			if b, _ := e.Bytes(); string(b) == member {
+				found = i
+			}
+		} else if found >= 0 {
+			score, _ := e.Float()
+			return found, score
+		}
+	}
+	return -1, 0
+}
+
+// Score returns the score of member.
+func (ss *SmallSortedSet) Score(member string) (float64, bool) {
+	if ss.tree != nil {
+		score, ok := ss.scores[member]
+		return score, ok
+	}
+	i, score := ss.index(member)
+	return score, i >= 0
+}
+
+// Add stores member with score, moving it if it was already there with
+// another score. It returns true if member is new.
+func (ss *SmallSortedSet) Add(member string, score float64) (bool, error) {
+	if math.IsNaN(score) {
+		return false, fmt.Errorf("score is NaN")
+	}
+	if ss.tree != nil {
+		return ss.addTree(member, score), nil
+	}
+
+	i, _ := ss.index(member)
+	if i >= 0 {
+		if err := ss.zl.RemoveRange(i, 2); err != nil {
+			return false, err
+		}
+	}
+	if len(member) > ss.maxValue || (i < 0 && ss.Len() >= ss.maxEntries) {
+		ss.promote()
+		return ss.addTree(member, score), nil
+	}
+
+	pos := 0
+	var m []byte
+	for j, e := range ss.zl.All() {
+		if j%2 == 0 {
+			m, _ = e.Bytes()
+			continue
+		}
+		s, _ := e.Float()
+		if s > score || (s == score && string(m) > member) {
+			break
+		}
+		pos = j + 1
+	}
+	if err := ss.zl.Insert(pos, member); err != nil {
+		return false, err
+	}
+	return i < 0, ss.zl.Insert(pos+1, score)
+}
+
+// Remove deletes member. It returns false if member was not stored.
+// A promoted set stays promoted.
+func (ss *SmallSortedSet) Remove(member string) bool {
+	if ss.tree != nil {
+		score, ok := ss.scores[member]
+		if ok {
+			ss.removeTree(member, score)
+		}
+		return ok
+	}
+	i, _ := ss.index(member)
+	if i < 0 {
+		return false
+	}
+	return ss.zl.RemoveRange(i, 2) == nil
+}
+
+// Rank returns the position of member in score order, starting at 0.
+func (ss *SmallSortedSet) Rank(member string) (int, bool) {
+	if ss.tree == nil {
+		i, _ := ss.index(member)
+		return i / 2, i >= 0
+	}
+	if _, ok := ss.scores[member]; !ok {
+		return 0, false
+	}
+	rank := 0
+	for m := range ss.All() {
+		if m == member {
+			break
+		}
+		rank++
+	}
+	return rank, true
+}
+
+// All returns the members and their scores in order.
+func (ss *SmallSortedSet) All() iter.Seq2[string, float64] {
+	return func(yield func(string, float64) bool) {
+		if ss.tree != nil {
+			for score, members := range ss.tree.All() {
+				for _, m := range members.([]string) {
+					if !yield(m, score) {
+						return
+					}
+				}
+			}
+			return
+		}
+		var member string
+		for i, e := range ss.zl.All() {
+			if i%2 == 0 {
+				b, _ := e.Bytes()
+				member = string(b)
+			} else if score, _ := e.Float(); !yield(member, score) {
+				return
+			}
+		}
+	}
+}
+
+func (ss *SmallSortedSet) promote() {
+	tree := rbtree.New[float64]()
+	scores := make(map[string]float64, ss.Len()+1)
+	for m, score := range ss.All() {
+		// All yields members in order, so each bucket stays sorted.
+		members, _ := tree.Search(score).([]string)
+		tree.Insert(score, append(members, m))
+		scores[m] = score
+	}
+	ss.tree, ss.scores = tree, scores
+	ss.zl = nil
+}
+
+func (ss *SmallSortedSet) addTree(member string, score float64) bool {
+	old, exists := ss.scores[member]
+	if exists {
+		if old == score {
+			return false
+		}
+		ss.removeTree(member, old)
+	}
+	members, _ := ss.tree.Search(score).([]string)
+	i, _ := slices.BinarySearch(members, member)
+	ss.tree.Insert(score, slices.Insert(members, i, member))
+	ss.scores[member] = score
+	return !exists
+}
+
+func (ss *SmallSortedSet) removeTree(member string, score float64) {
+	members := ss.tree.Search(score).([]string)
+	i, _ := slices.BinarySearch(members, member)
+	members = slices.Delete(members, i, i+1)
+	if len(members) == 0 {
+		ss.tree.Delete(score)
+	} else {
+		ss.tree.Insert(score, members)
+	}
+	delete(ss.scores, member)
+}
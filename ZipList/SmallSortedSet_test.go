@@ -0,0 +1,75 @@
+package ziplist
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type scored struct {
+	member string
+	score  float64
+}
+
+func TestSmallSortedSetAgainstModel(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, limits := range [][2]int{{6, 8}, {0, 0}} {
+		ss := NewSmallSortedSet(limits[0], limits[1])
+		ref := map[string]float64{}
+		for step := 0; step < 2000; step++ {
+			m := fmt.Sprint("m", r.Intn(40))
+			if step == 1500 {
+				// A long member promotes the set.
+				m = strings.Repeat("z", 100)
+			}
+			_, had := ref[m]
+			if r.Intn(3) == 0 {
+				if ss.Remove(m) != had {
+					t.Fatalf("Remove(%q) != %v", m, had)
+				}
+				delete(ref, m)
+			} else {
+				score := float64(r.Intn(5))
+				added, err := ss.Add(m, score)
+				if err != nil || added == had {
+					t.Fatalf("Add(%q) = %v, %v", m, added, err)
+				}
+				ref[m] = score
+			}
+
+			var want []scored
+			for m, s := range ref {
+				want = append(want, scored{m, s})
+			}
+			slices.SortFunc(want, func(a, b scored) int {
+				return cmp.Or(cmp.Compare(a.score, b.score), strings.Compare(a.member, b.member))
+			})
+			var got []scored
+			for m, s := range ss.All() {
+				got = append(got, scored{m, s})
+			}
+			if !slices.Equal(got, want) || ss.Len() != len(want) {
+				t.Fatalf("All = %v, want %v", got, want)
+			}
+			for i, p := range want {
+				if rank, ok := ss.Rank(p.member); !ok || rank != i {
+					t.Fatalf("Rank(%q) = %d, %v; want %d", p.member, rank, ok, i)
+				}
+				if s, ok := ss.Score(p.member); !ok || s != p.score {
+					t.Fatalf("Score(%q) = %v, %v", p.member, s, ok)
+				}
+			}
+		}
+		if limits[0] != 0 && !ss.Promoted() {
+			t.Fatal("set with small limits was never promoted")
+		}
+	}
+
+	if _, err := NewSmallSortedSet(0, 0).Add("nan", math.NaN()); err == nil {
+		t.Fatal("NaN score accepted")
+	}
+}
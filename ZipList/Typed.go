@@ -0,0 +1,107 @@
+package ziplist
+
+import "fmt"
+
+// TypeError is returned by the typed accessors when the entry cannot be read
+// as the requested type.
+type TypeError struct {
+	Index int
+	Type  byte   // type tag of the entry, as reported by Entry.Type
+	Want  string // requested Go type
+}
+
+func (e *TypeError) Error() string {
+	return fmt.Sprintf("ziplist: entry %d of type %#x cannot be read as %s", e.Index, e.Type, e.Want)
+}
+
+func (zl *Ziplist) entry(index int) (Entry, error) {
+	if index < 0 || index >= zl.Len() {
+		return Entry{}, fmt.Errorf("index out of range")
+	}
+	offset, err := zl.offsetOf(index)
+	if err != nil {
+		return Entry{}, err
+	}
+	e, _, err := zl.entryAt(offset)
+	return e, err
+}
+
+// IntAt returns the integer at index as an int64. Every integer type is
+// accepted, except a uint64 above math.MaxInt64.
+func (zl *Ziplist) IntAt(index int) (int64, error) {
+	e, err := zl.entry(index)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := e.Int()
+	if !ok {
+		return 0, &TypeError{index, e.typ, "int64"}
+	}
+	return n, nil
+}
+
+// UintAt returns the integer at index as a uint64. Every integer type is
+// accepted, as long as the value is not negative.
+func (zl *Ziplist) UintAt(index int) (uint64, error) {
+	e, err := zl.entry(index)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := e.Uint()
+	if !ok {
+		return 0, &TypeError{index, e.typ, "uint64"}
+	}
+	return n, nil
+}
+
+// FloatAt returns the float32 or float64 at index as a float64.
+func (zl *Ziplist) FloatAt(index int) (float64, error) {
+	e, err := zl.entry(index)
+	if err != nil {
+		return 0, err
+	}
+	f, ok := e.Float()
+	if !ok {
+		return 0, &TypeError{index, e.typ, "float64"}
+	}
+	return f, nil
+}
+
+func (zl *Ziplist) BoolAt(index int) (bool, error) {
+	e, err := zl.entry(index)
+	if err != nil {
+		return false, err
+	}
+	b, ok := e.Bool()
+	if !ok {
+		return false, &TypeError{index, e.typ, "bool"}
+	}
+	return b, nil
+}
+
+// StringAt returns a copy of the string or blob at index.
+func (zl *Ziplist) StringAt(index int) (string, error) {
+	e, err := zl.entry(index)
+	if err != nil {
+		return "", err
+	}
+	b, ok := e.Bytes()
+	if !ok {
+		return "", &TypeError{index, e.typ, "string"}
+	}
+	return string(b), nil
+}
+
+// BytesAt returns the string or blob at index without copying it. The slice
+// points into the list and is only valid until the list is modified.
+func (zl *Ziplist) BytesAt(index int) ([]byte, error) {
+	e, err := zl.entry(index)
+	if err != nil {
+		return nil, err
+	}
+	b, ok := e.Bytes()
+	if !ok {
+		return nil, &TypeError{index, e.typ, "[]byte"}
+	}
+	return b, nil
+}
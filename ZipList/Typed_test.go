@@ -0,0 +1,134 @@
+package ziplist
+
+import (
+	"errors"
+	"testing"
+)
+
+// typedList holds one entry for each typed accessor, commented with it.
+func typedList() *Ziplist {
+	zl := New(0)
+	zl.Push(int16(-7))     // IntAt
+	zl.Push(uint32(7))     // UintAt
+	zl.Push(float32(0.5))  // FloatAt
+	zl.Push(true)          // BoolAt
+	zl.Push([]byte("abc")) // BytesAt
+	zl.Push("abc")         // StringAt
+	return zl
+}
+
+func TestTypedAccessors(t *testing.T) {
+	zl := typedList()
+	if n, err := zl.IntAt(0); err != nil || n != -7 {
+		t.Errorf("IntAt = %d, %v", n, err)
+	}
+	if n, err := zl.UintAt(1); err != nil || n != 7 {
+		t.Errorf("UintAt = %d, %v", n, err)
+	}
+	if f, err := zl.FloatAt(2); err != nil || f != 0.5 {
+		t.Errorf("FloatAt = %v, %v", f, err)
+	}
+	if b, err := zl.BoolAt(3); err != nil || !b {
+		t.Errorf("BoolAt = %v, %v", b, err)
+	}
+	if b, err := zl.BytesAt(4); err != nil || string(b) != "abc" {
+		t.Errorf("BytesAt = %q, %v", b, err)
+	}
+	if s, err := zl.StringAt(5); err != nil || s != "abc" {
+		t.Errorf("StringAt = %q, %v", s, err)
+	}
+
+	// Widening: any integer reads through IntAt, a non-negative one
+	// through UintAt.
+	if n, err := zl.IntAt(1); err != nil || n != 7 {
+		t.Errorf("IntAt(uint32) = %d, %v", n, err)
+	}
+	if _, err := zl.IntAt(99); err == nil {
+		t.Error("IntAt out of range succeeded")
+	}
+}
+
+func TestTypedMismatch(t *testing.T) {
+	zl := typedList()
+	checks := map[string]error{}
+	_, checks["IntAt(float)"] = zl.IntAt(2)
+	_, checks["UintAt(negative)"] = zl.UintAt(0)
+	_, checks["FloatAt(int)"] = zl.FloatAt(0)
+	_, checks["BoolAt(string)"] = zl.BoolAt(5)
+	_, checks["StringAt(bool)"] = zl.StringAt(3)
+	_, checks["BytesAt(uint)"] = zl.BytesAt(1)
+
+	big := New(0)
+	big.Push(uint64(1 << 63))
+	_, checks["IntAt(uint64 overflow)"] = big.IntAt(0)
+
+	for name, err := range checks {
+		var te *TypeError
+		if !errors.As(err, &te) {
+			t.Errorf("%s: got %v, want *TypeError", name, err)
+		}
+	}
+}
+
+func TestTypedZeroAllocs(t *testing.T) {
+	zl := typedList()
+	allocs := testing.AllocsPerRun(100, func() {
+		zl.IntAt(0)
+		zl.UintAt(1)
+		zl.FloatAt(2)
+		zl.BoolAt(3)
+		zl.BytesAt(4)
+	})
+	if allocs != 0 {
+		t.Fatalf("typed accessors allocate %v times per run, want 0", allocs)
+	}
+}
+
+func BenchmarkIntAt(b *testing.B) {
+	zl := typedList()
+	b.ReportAllocs()
+	for range b.N {
+		zl.IntAt(0)
+	}
+}
+
+func BenchmarkUintAt(b *testing.B) {
+	zl := typedList()
+	b.ReportAllocs()
+	for range b.N {
+		zl.UintAt(1)
+	}
+}
+
+func BenchmarkFloatAt(b *testing.B) {
+	zl := typedList()
+	b.ReportAllocs()
+	for range b.N {
+		zl.FloatAt(2)
+	}
+}
+
+func BenchmarkBoolAt(b *testing.B) {
+	zl := typedList()
+	b.ReportAllocs()
+	for range b.N {
+		zl.BoolAt(3)
+	}
+}
+
+func BenchmarkBytesAt(b *testing.B) {
+	zl := typedList()
+	b.ReportAllocs()
+	for range b.N {
+		zl.BytesAt(4)
+	}
+}
+
+// BenchmarkAt is the boxing baseline the typed accessors avoid.
+func BenchmarkAt(b *testing.B) {
+	zl := typedList()
+	b.ReportAllocs()
+	for range b.N {
+		zl.At(0)
+	}
+}
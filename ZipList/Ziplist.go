@@ -4,6 +4,7 @@ import (
 	"encoding/binary"
 	"fmt"
 	"math"
+	"slices"
 )
 
 const (
@@ -28,264 +29,517 @@ const (
 	//Base
 	TYPE_BOOL   = 0x0a
 	TYPE_STRING = 0x0b
+	TYPE_BLOB   = 0x0c
 
 	TYPE_LEN        = 0x0d
+	TYPE_TAIL       = 0x0e
 	TYPE_TOTAL_BYTE = 0x0f
+
+	//Compact strings, the length is packed in the type byte:
+	//01pppppp qqqqqqqq for up to 14 bits, 10pppppp for up to 6 bits.
+	//Longer strings use TYPE_STRING with a 32-bit length.
+	TYPE_STR14 = 0x40
+	TYPE_STR6  = 0x80
+
+	//Compact integers, written by a compact ziplist and read back as int64
+	TYPE_CINT8  = 0xc0
+	TYPE_CINT16 = 0xc1
+	TYPE_CINT24 = 0xc2
+	TYPE_CINT32 = 0xc3
+	TYPE_CINT64 = 0xc4
+
+	//Immediate integers 0..12, stored in the type byte itself (1111xxxx)
+	TYPE_IMM    = 0xf0
+	TYPE_IMMMAX = 0xfc
+)
+
+// Header layout:
+//
+//	Offset 0:  TYPE_TOTAL_BYTE (1 byte)
+//	Offset 1:  Total Byte (4 byte)
+//	Offset 5:  TYPE_LEN (1 byte)
+//	Offset 6:  Len (4 byte)
+//	Offset 10: TYPE_TAIL (1 byte)
+//	Offset 11: Offset of the last entry, or of TYPE_END when empty (4 byte)
+//
+// Each entry then starts with the size of the previous entry (0 for the
+// first one): 1 byte below prevlenBig, else prevlenBig and 4 bytes. This is
+// what allows walking the list backwards.
+const (
+	totalOffset = 1
+	countOffset = 6
+	tailOffset  = 11
+	headerSize  = 15
+
+	prevlenBig = 0xfe
 )
 
 type Ziplist struct {
-	bytes  []byte
-	cursor uint32
+	bytes   []byte
+	cursor  uint32
+	compact bool
+	numeric bool
 }
 
 func New(capacity uint32) *Ziplist {
 	zl := &Ziplist{bytes: make([]byte, 0, capacity)}
+	zl.resetHeader()
+	return zl
+}
+
+// NewCompact returns a ziplist that narrows every integer it stores to the
+// smallest encoding that holds it, down to no payload at all for 0..12.
+// Integers are then read back as int64, whatever type they were pushed as,
+// except uint64 values above math.MaxInt64.
+func NewCompact(capacity uint32) *Ziplist {
+	zl := New(capacity)
+	zl.compact = true
+	return zl
+}
+
+func (zl *Ziplist) resetHeader() {
+	zl.bytes = zl.bytes[:0]
 	//SET HEADER
 	zl.bytes = append(zl.bytes, TYPE_TOTAL_BYTE)
 	zl.bytes = binary.LittleEndian.AppendUint32(zl.bytes, 0)
 	zl.bytes = append(zl.bytes, TYPE_LEN)
 	zl.bytes = binary.LittleEndian.AppendUint32(zl.bytes, 0)
+	zl.bytes = append(zl.bytes, TYPE_TAIL)
+	zl.bytes = binary.LittleEndian.AppendUint32(zl.bytes, headerSize)
 	zl.bytes = append(zl.bytes, TYPE_END)
 	currentLen := uint32(len(zl.bytes))
-	binary.LittleEndian.PutUint32(zl.bytes[1:5], currentLen)
-
-	return zl
+	binary.LittleEndian.PutUint32(zl.bytes[totalOffset:totalOffset+4], currentLen)
 }
 
 func (zl *Ziplist) getElementSize(offset int) (int, error) {
 	if offset >= len(zl.bytes) {
 		return 0, fmt.Errorf("out of bounds")
 	}
-
-	typ := zl.bytes[offset]
-
-	switch typ {
-	case TYPE_END:
+	if zl.bytes[offset] == TYPE_END {
 		return 0, nil
+	}
 
-	case TYPE_UINT8, TYPE_INT8, TYPE_BOOL:
-		return 1 + 1, nil
-	case TYPE_UINT16, TYPE_INT16:
-		return 1 + 2, nil
-	case TYPE_UINT32, TYPE_INT32, TYPE_FLOAT32:
-		return 1 + 4, nil
-	case TYPE_UINT64, TYPE_INT64, TYPE_FLOAT64:
-		return 1 + 8, nil
+	_, dataStart, dataLen, err := zl.layout(offset)
+	if err != nil {
+		return 0, err
+	}
+	return dataStart + dataLen - offset, nil
+}
 
-	case TYPE_STRING:
+func (zl *Ziplist) Remove(index int) error {
+	return zl.RemoveRange(index, 1)
+}
 
-		if offset+5 > len(zl.bytes) {
-			return 0, fmt.Errorf("malformed string header")
-		}
-		strLen := binary.LittleEndian.Uint32(zl.bytes[offset+1 : offset+5])
-		return 1 + 4 + int(strLen), nil
+// RemoveRange removes n entries starting at index start.
+func (zl *Ziplist) RemoveRange(start, n int) error {
+	currentCount := zl.Len()
+	if start < 0 || n < 0 || start+n > currentCount {
+		return fmt.Errorf("index out of range")
+	}
+	if n == 0 {
+		return nil
+	}
 
-	default:
-		return 0, fmt.Errorf("unknown type: %x", typ)
+	from, err := zl.offsetOf(start)
+	if err != nil {
+		return err
+	}
+	to := from
+	for i := 0; i < n; i++ {
+		size, err := zl.getElementSize(to)
+		if err != nil {
+			return err
+		}
+		to += size
+	}
+	prevSize, _, err := zl.prevlen(from)
+	if err != nil {
+		return err
 	}
-}
 
-func (zl *Ziplist) Remove(index int) error {
+	zl.bytes = append(zl.bytes[:from], zl.bytes[to:]...)
+	zl.setHeader(currentCount - n)
 
-	countIdx := 6
-	currentCount := binary.LittleEndian.Uint32(zl.bytes[countIdx : countIdx+4])
+	if zl.bytes[from] == TYPE_END {
+		// The removed run included the tail: the entry before it is the
+		// new tail, or the list is empty.
+		if from == headerSize {
+			zl.setTail(headerSize)
+		} else {
+			zl.setTail(from - prevSize)
+		}
+		return nil
+	}
+	zl.setTail(zl.tail() - (to - from))
+	zl.cascade(from, prevSize)
+	return nil
+}
 
-	if index < 0 || uint32(index) >= currentCount {
+// Insert places value at index, shifting the following entries back.
+// index may be equal to Len(), which appends.
+func (zl *Ziplist) Insert(index int, value any) error {
+	if index < 0 || index > zl.Len() {
 		return fmt.Errorf("index out of range")
 	}
+	offset, err := zl.offsetOf(index)
+	if err != nil {
+		return err
+	}
+	return zl.insertAt(offset, value)
+}
 
-	currentPos := 10
+// insertAt places value at offset, which is an entry or the end marker.
+func (zl *Ziplist) insertAt(offset int, value any) error {
+	atEnd := zl.bytes[offset] == TYPE_END
 
-	for i := 0; i < index; i++ {
-		size, err := zl.getElementSize(currentPos)
-		if err != nil {
+	prevSize := 0
+	if atEnd {
+		if zl.Len() > 0 {
+			prevSize = offset - zl.tail()
+		}
+	} else {
+		var err error
+		if prevSize, _, err = zl.prevlen(offset); err != nil {
 			return err
 		}
-		currentPos += size
 	}
 
-	sizeToRemove, err := zl.getElementSize(currentPos)
+	entry := appendPrevlen(nil, prevSize)
+	entry, err := appendValue(entry, value, zl.compact)
 	if err != nil {
 		return err
 	}
 
-	copy(zl.bytes[currentPos:], zl.bytes[currentPos+sizeToRemove:])
+	zl.bytes = slices.Insert(zl.bytes, offset, entry...)
+	zl.setHeader(zl.Len() + 1)
+
+	if atEnd {
+		zl.setTail(offset)
+		return nil
+	}
+	zl.setTail(zl.tail() + len(entry))
+	zl.cascade(offset+len(entry), len(entry))
+	return nil
+}
 
-	zl.bytes = zl.bytes[:len(zl.bytes)-sizeToRemove]
+// PushFront inserts value before the first entry.
+func (zl *Ziplist) PushFront(value any) error {
+	return zl.Insert(0, value)
+}
+
+// Set replaces the entry at index with value. The following entries are
+// moved if the new encoding is longer or shorter than the old one.
+func (zl *Ziplist) Set(index int, value any) error {
+	if index < 0 || index >= zl.Len() {
+		return fmt.Errorf("index out of range")
+	}
+	offset, err := zl.offsetOf(index)
+	if err != nil {
+		return err
+	}
+	size, err := zl.getElementSize(offset)
+	if err != nil {
+		return err
+	}
+	prevSize, _, err := zl.prevlen(offset)
+	if err != nil {
+		return err
+	}
 
-	binary.LittleEndian.PutUint32(zl.bytes[countIdx:countIdx+4], currentCount-1)
+	entry := appendPrevlen(nil, prevSize)
+	entry, err = appendValue(entry, value, zl.compact)
+	if err != nil {
+		return err
+	}
 
-	totalLenIdx := 1
-	binary.LittleEndian.PutUint32(zl.bytes[totalLenIdx:totalLenIdx+4], uint32(len(zl.bytes)))
+	zl.bytes = slices.Replace(zl.bytes, offset, offset+size, entry...)
+	zl.setHeader(zl.Len())
 
+	if offset != zl.tail() {
+		zl.setTail(zl.tail() + len(entry) - size)
+		zl.cascade(offset+len(entry), len(entry))
+	}
 	return nil
 }
 
+// cascade stores prevSize as the previous-entry length of the entry at
+// offset. If the field has to grow from 1 to 5 bytes, that entry grows too,
+// and the update moves on to the next one. A 5-byte field is never shrunk,
+// which keeps a removal from cascading.
+func (zl *Ziplist) cascade(offset, prevSize int) {
+	for zl.bytes[offset] != TYPE_END {
+		old, field, err := zl.prevlen(offset)
+		if err != nil || old == prevSize {
+			return
+		}
+
+		if prevSize < prevlenBig || field == 5 {
+			if field == 1 {
+				zl.bytes[offset] = byte(prevSize)
+			} else {
+				binary.LittleEndian.PutUint32(zl.bytes[offset+1:offset+5], uint32(prevSize))
+			}
+			return
+		}
+
+		size, err := zl.getElementSize(offset)
+		if err != nil {
+			return
+		}
+		zl.bytes = slices.Replace(zl.bytes, offset, offset+1, appendPrevlen(nil, prevSize)...)
+		zl.setHeader(zl.Len())
+		if offset < zl.tail() {
+			zl.setTail(zl.tail() + 4)
+		}
+		prevSize = size + 4
+		offset += prevSize
+	}
+}
+
+// Len returns the number of entries.
+func (zl *Ziplist) Len() int {
+	return int(binary.LittleEndian.Uint32(zl.bytes[countOffset : countOffset+4]))
+}
+
+// Size returns the encoded length of the list in bytes, header included.
+func (zl *Ziplist) Size() int {
+	return len(zl.bytes)
+}
+
+// offsetOf returns the byte offset of the entry at index, or of the end
+// marker when index is Len(). Entries in the second half of the list are
+// reached walking backwards from the tail.
+func (zl *Ziplist) offsetOf(index int) (int, error) {
+	count := zl.Len()
+	if index == count {
+		return len(zl.bytes) - 1, nil
+	}
+
+	if index >= count/2 {
+		offset := zl.tail()
+		for i := count - 1; i > index; i-- {
+			prev, _, err := zl.prevlen(offset)
+			if err != nil {
+				return 0, err
+			}
+			if prev == 0 || offset-prev < headerSize {
+				return 0, fmt.Errorf("malformed previous entry length")
+			}
+			offset -= prev
+		}
+		return offset, nil
+	}
+
+	offset := headerSize
+	for i := 0; i < index; i++ {
+		size, err := zl.getElementSize(offset)
+		if err != nil {
+			return 0, err
+		}
+		offset += size
+	}
+	return offset, nil
+}
+
 func (zl *Ziplist) Clear() {
-	zl.bytes = zl.bytes[:0]
-	zl.bytes = append(zl.bytes, TYPE_TOTAL_BYTE)
-	zl.bytes = binary.LittleEndian.AppendUint32(zl.bytes, 0)
-	zl.bytes = append(zl.bytes, TYPE_LEN)
-	zl.bytes = binary.LittleEndian.AppendUint32(zl.bytes, 0)
-	zl.bytes = append(zl.bytes, TYPE_END)
-	currentLen := uint32(len(zl.bytes))
-	binary.LittleEndian.PutUint32(zl.bytes[1:5], currentLen)
+	zl.resetHeader()
 }
 
 func (zl *Ziplist) Push(value any) error {
+	return zl.insertAt(len(zl.bytes)-1, value)
+}
+
+// AppendRange appends n entries of src, starting at index start, copying
+// their encoding as is: nothing is decoded, and compact integers keep their
+// width. Only the previous-entry lengths are rewritten. src may be zl.
+func (zl *Ziplist) AppendRange(src *Ziplist, start, n int) error {
+	if start < 0 || n < 0 || start+n > src.Len() {
+		return fmt.Errorf("index out of range")
+	}
+	if n == 0 {
+		return nil
+	}
+	offset, err := src.offsetOf(start)
+	if err != nil {
+		return err
+	}
+	start = offset // offset of the entry being copied; offset moves past it
+
+	// Entries are appended in place of the end marker; a source inside zl
+	// lies before it, so the copy never overwrites what it still has to read.
+	end := len(zl.bytes) - 1
+	prevSize, tail, count := 0, zl.tail(), zl.Len()
+	if count > 0 {
+		prevSize = end - tail
+	}
+	dst := zl.bytes[:end]
+	for i := 0; i < n; i++ {
+		_, field, err := src.prevlen(offset)
+		if err == nil {
+			_, offset, err = src.entryAt(offset)
+		}
+		if err != nil {
+			// dst may share zl's array: put the end marker back.
+			zl.bytes[end] = TYPE_END
+			return err
+		}
+		tail = len(dst)
+		dst = appendPrevlen(dst, prevSize)
+		dst = append(dst, src.bytes[start+field:offset]...)
+		prevSize = len(dst) - tail
+		start = offset
+	}
 
-	if len(zl.bytes) > 0 && zl.bytes[len(zl.bytes)-1] == TYPE_END {
-		zl.bytes = zl.bytes[:len(zl.bytes)-1]
+	zl.bytes = append(dst, TYPE_END)
+	zl.setTail(tail)
+	zl.setHeader(count + n)
+	return nil
+}
+
+// appendValue appends the encoding of value (type byte and payload) to dst.
+// int and uint are stored as 64-bit values. With compact set, integers are
+// narrowed, see NewCompact.
+func appendValue(dst []byte, value any, compact bool) ([]byte, error) {
+	if compact {
+		if n, ok := asInt64(value); ok {
+			return appendCompactInt(dst, n), nil
+		}
 	}
 
 	switch v := value.(type) {
 
 	case uint8:
-		zl.bytes = append(zl.bytes, TYPE_UINT8)
-		zl.bytes = append(zl.bytes, v)
+		dst = append(dst, TYPE_UINT8)
+		dst = append(dst, v)
 
 	case uint16:
-		zl.bytes = append(zl.bytes, TYPE_UINT16)
-		zl.bytes = binary.LittleEndian.AppendUint16(zl.bytes, v)
+		dst = append(dst, TYPE_UINT16)
+		dst = binary.LittleEndian.AppendUint16(dst, v)
 
 	case uint32:
-		zl.bytes = append(zl.bytes, TYPE_UINT32)
-		zl.bytes = binary.LittleEndian.AppendUint32(zl.bytes, v)
+		dst = append(dst, TYPE_UINT32)
+		dst = binary.LittleEndian.AppendUint32(dst, v)
+
+	case uint64:
+		dst = append(dst, TYPE_UINT64)
+		dst = binary.LittleEndian.AppendUint64(dst, v)
+
+	case uint:
+		dst = append(dst, TYPE_UINT64)
+		dst = binary.LittleEndian.AppendUint64(dst, uint64(v))
+
+	case int8:
+		dst = append(dst, TYPE_INT8)
+		dst = append(dst, uint8(v))
+
+	case int16:
+		dst = append(dst, TYPE_INT16)
+		dst = binary.LittleEndian.AppendUint16(dst, uint16(v))
 
 	case int32:
-		zl.bytes = append(zl.bytes, TYPE_INT32)
-		zl.bytes = binary.LittleEndian.AppendUint32(zl.bytes, uint32(v))
+		dst = append(dst, TYPE_INT32)
+		dst = binary.LittleEndian.AppendUint32(dst, uint32(v))
+
+	case int64:
+		dst = append(dst, TYPE_INT64)
+		dst = binary.LittleEndian.AppendUint64(dst, uint64(v))
+
+	case int:
+		dst = append(dst, TYPE_INT64)
+		dst = binary.LittleEndian.AppendUint64(dst, uint64(v))
+
+	case float32:
+		dst = append(dst, TYPE_FLOAT32)
+		dst = binary.LittleEndian.AppendUint32(dst, math.Float32bits(v))
 
 	case float64:
-		zl.bytes = append(zl.bytes, TYPE_FLOAT64)
+		dst = append(dst, TYPE_FLOAT64)
 		bits := math.Float64bits(v)
-		zl.bytes = binary.LittleEndian.AppendUint64(zl.bytes, bits)
+		dst = binary.LittleEndian.AppendUint64(dst, bits)
 
 	case string:
-		zl.bytes = append(zl.bytes, TYPE_STRING)
-		strLen := uint32(len(v))
-		zl.bytes = binary.LittleEndian.AppendUint32(zl.bytes, strLen)
-		zl.bytes = append(zl.bytes, []byte(v)...)
+		dst = appendStringHeader(dst, len(v))
+		dst = append(dst, v...)
+
+	case []byte:
+		dst = append(dst, TYPE_BLOB)
+		dst = binary.LittleEndian.AppendUint32(dst, uint32(len(v)))
+		dst = append(dst, v...)
 
 	case bool:
-		zl.bytes = append(zl.bytes, TYPE_BOOL)
+		dst = append(dst, TYPE_BOOL)
 		if v {
-			zl.bytes = append(zl.bytes, 1)
+			dst = append(dst, 1)
 		} else {
-			zl.bytes = append(zl.bytes, 0)
+			dst = append(dst, 0)
 		}
 
 	default:
-		zl.bytes = append(zl.bytes, TYPE_END)
-		return fmt.Errorf("tipo non supportato: %T", v)
+		return dst, fmt.Errorf("tipo non supportato: %T", v)
 	}
-
-	zl.bytes = append(zl.bytes, TYPE_END)
-
-	zl.updateHeader()
-
-	return nil
+	return dst, nil
 }
 
 // At returns the value at the given index.
 // It returns an error if the index is out of bounds.
 func (zl *Ziplist) At(index int) (any, error) {
-	// 1. Read the current number of items from the header (bytes 6-10)
-	countIdx := 6
-	if len(zl.bytes) < 10 {
-		return nil, fmt.Errorf("ziplist is empty or malformed")
-	}
-	currentCount := binary.LittleEndian.Uint32(zl.bytes[countIdx : countIdx+4])
-
-	// 2. Bounds Check
-	if index < 0 || uint32(index) >= currentCount {
+	// 1. Bounds Check
+	if index < 0 || index >= zl.Len() {
 		return nil, fmt.Errorf("index out of range")
 	}
 
-	// 3. Traverse the list to find the start offset of the requested index
-	// Start after the header (10 bytes)
-	cursor := 10
-	for i := 0; i < index; i++ {
-		size, err := zl.getElementSize(cursor)
-		if err != nil {
-			return nil, err
-		}
-		cursor += size
-	}
-
-	// 4. Decode the value at the current cursor
-	if cursor >= len(zl.bytes) {
-		return nil, fmt.Errorf("cursor out of bounds")
+	// 2. Find the start offset of the requested index
+	cursor, err := zl.offsetOf(index)
+	if err != nil {
+		return nil, err
 	}
 
-	typ := zl.bytes[cursor]
-	// Advance cursor past the type byte
-	dataStart := cursor + 1
-
-	switch typ {
-	case TYPE_UINT8:
-		return zl.bytes[dataStart], nil
-
-	case TYPE_UINT16:
-		return binary.LittleEndian.Uint16(zl.bytes[dataStart : dataStart+2]), nil
-
-	case TYPE_UINT32:
-		return binary.LittleEndian.Uint32(zl.bytes[dataStart : dataStart+4]), nil
-
-	case TYPE_UINT64:
-		return binary.LittleEndian.Uint64(zl.bytes[dataStart : dataStart+8]), nil
-
-	case TYPE_INT8:
-		// Go doesn't have a direct LittleEndian.Uint8, just cast the byte
-		return int8(zl.bytes[dataStart]), nil
-
-	case TYPE_INT16:
-		return int16(binary.LittleEndian.Uint16(zl.bytes[dataStart : dataStart+2])), nil
-
-	case TYPE_INT32:
-		return int32(binary.LittleEndian.Uint32(zl.bytes[dataStart : dataStart+4])), nil
-
-	case TYPE_INT64:
-		return int64(binary.LittleEndian.Uint64(zl.bytes[dataStart : dataStart+8])), nil
-
-	case TYPE_FLOAT32:
-		bits := binary.LittleEndian.Uint32(zl.bytes[dataStart : dataStart+4])
-		return math.Float32frombits(bits), nil
-
-	case TYPE_FLOAT64:
-		bits := binary.LittleEndian.Uint64(zl.bytes[dataStart : dataStart+8])
-		return math.Float64frombits(bits), nil
-
-	case TYPE_BOOL:
-		val := zl.bytes[dataStart]
-		return val == 1, nil
+	// 3. Decode the value at the current cursor
+	return zl.valueAt(cursor)
+}
 
-	case TYPE_STRING:
-		// String format: [Type] [Len (4 bytes)] [String Data]
-		strLen := binary.LittleEndian.Uint32(zl.bytes[dataStart : dataStart+4])
-		strBodyStart := dataStart + 4
-		strBodyEnd := strBodyStart + int(strLen)
+// Last returns the value of the last entry, without walking the list.
+func (zl *Ziplist) Last() (any, error) {
+	if zl.Len() == 0 {
+		return nil, fmt.Errorf("ziplist is empty")
+	}
+	return zl.valueAt(zl.tail())
+}
 
-		if strBodyEnd > len(zl.bytes) {
-			return nil, fmt.Errorf("malformed string length")
-		}
-		return string(zl.bytes[strBodyStart:strBodyEnd]), nil
+// Pop removes the last entry and returns its value.
+func (zl *Ziplist) Pop() (any, error) {
+	val, err := zl.Last()
+	if err != nil {
+		return nil, err
+	}
+	if err := zl.RemoveRange(zl.Len()-1, 1); err != nil {
+		return nil, err
+	}
+	return val, nil
+}
 
-	case TYPE_END:
+func (zl *Ziplist) valueAt(offset int) (any, error) {
+	if zl.bytes[offset] == TYPE_END {
 		return nil, fmt.Errorf("accessed end of list unexpectedly")
-
-	default:
-		return nil, fmt.Errorf("unknown type at index %d: %x", index, typ)
 	}
+	typ, dataStart, dataLen, err := zl.layout(offset)
+	if err != nil {
+		return nil, err
+	}
+	return decode(typ, zl.bytes[dataStart:dataStart+dataLen]), nil
+}
+
+func (zl *Ziplist) tail() int {
+	return int(binary.LittleEndian.Uint32(zl.bytes[tailOffset : tailOffset+4]))
+}
+
+func (zl *Ziplist) setTail(offset int) {
+	binary.LittleEndian.PutUint32(zl.bytes[tailOffset:tailOffset+4], uint32(offset))
 }
 
-func (zl *Ziplist) updateHeader() {
-	// Offset 0: TYPE_TOTAL_BYTE (1 byte)
-	// Offset 1: Total Byte (4 byte)
-	// Offset 5: TYPE_LEN (1 byte)
-	// Offset 6: Valore Len (4 byte)
-	totalLen := uint32(len(zl.bytes))
-	binary.LittleEndian.PutUint32(zl.bytes[1:5], totalLen)
-	currentCount := binary.LittleEndian.Uint32(zl.bytes[6:10])
-	binary.LittleEndian.PutUint32(zl.bytes[6:10], currentCount+1)
+// setHeader stores count as the number of entries and refreshes the total
+// byte length.
+func (zl *Ziplist) setHeader(count int) {
+	binary.LittleEndian.PutUint32(zl.bytes[totalOffset:totalOffset+4], uint32(len(zl.bytes)))
+	binary.LittleEndian.PutUint32(zl.bytes[countOffset:countOffset+4], uint32(count))
 }
@@ -0,0 +1,383 @@
+package ziplist
+
+import (
+	"bytes"
+	"math"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// checkList fails unless zl is well formed and holds want, read both ways.
+func checkList(t *testing.T, zl *Ziplist, want []any) {
+	t.Helper()
+	if err := zl.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if zl.Len() != len(want) {
+		t.Fatalf("Len() = %d, want %d", zl.Len(), len(want))
+	}
+	for i := range want {
+		if got, err := zl.At(i); err != nil || !reflect.DeepEqual(got, want[i]) {
+			t.Fatalf("At(%d) = %#v, %v; want %#v", i, got, err, want[i])
+		}
+	}
+	if _, err := zl.At(len(want)); err == nil {
+		t.Fatal("At past the end succeeded")
+	}
+	seen := 0
+	for i, e := range zl.All() {
+		if got := e.Value(); !reflect.DeepEqual(got, want[i]) {
+			t.Fatalf("All: entry %d = %#v, want %#v", i, got, want[i])
+		}
+		seen++
+	}
+	for i, e := range zl.Backward() {
+		if got := e.Value(); !reflect.DeepEqual(got, want[i]) {
+			t.Fatalf("Backward: entry %d = %#v, want %#v", i, got, want[i])
+		}
+		seen--
+	}
+	if seen != 0 {
+		t.Fatalf("All and Backward yield different counts")
+	}
+}
+
+// everyType holds a value of every type a Ziplist can store, with strings
+// long enough to need each string header.
+var everyType = []any{
+	uint8(1), uint16(2), uint32(3), uint64(math.MaxUint64),
+	int8(-1), int16(-2), int32(-3), int64(math.MinInt64),
+	int(7), uint(8),
+	float32(1.5), 2.5,
+	true, false,
+	"", "short", strings.Repeat("m", 300), strings.Repeat("l", 20000),
+	[]byte{}, []byte{0, 1, 0xff},
+}
+
+// values returns the decoded entries of zl.
+func values(zl *Ziplist) []any {
+	var vs []any
+	for _, e := range zl.All() {
+		vs = append(vs, e.Value())
+	}
+	return vs
+}
+
+// seedLists returns an empty list and lists holding everyType, plain and
+// compact.
+func seedLists() []*Ziplist {
+	plain := New(0)
+	compact := NewCompact(0)
+	for _, v := range everyType {
+		plain.Push(v)
+		compact.Push(v)
+	}
+	for _, n := range []int64{0, 12, 13, -128, 300, 1 << 20, 1 << 30, 1 << 40} {
+		compact.Push(n)
+	}
+	return []*Ziplist{New(0), plain, compact}
+}
+
+func TestPushEveryType(t *testing.T) {
+	zl := New(0)
+	var want []any
+	for _, v := range everyType {
+		if err := zl.Push(v); err != nil {
+			t.Fatalf("Push(%T): %v", v, err)
+		}
+		// int and uint are stored as their 64-bit types.
+		switch n := v.(type) {
+		case int:
+			v = int64(n)
+		case uint:
+			v = uint64(n)
+		}
+		want = append(want, v)
+	}
+	checkList(t, zl, want)
+
+	before := bytes.Clone(zl.bytes)
+	for _, v := range []any{struct{}{}, complex(1, 2), nil, []int{1}} {
+		if err := zl.Push(v); err == nil {
+			t.Errorf("Push(%T) succeeded", v)
+		}
+	}
+	if !bytes.Equal(zl.bytes, before) {
+		t.Fatal("a failed Push changed the list")
+	}
+}
+
+// editValue returns a value for the edit tests. Strings of 248 to 256 bytes
+// make entries on both sides of the 254-byte limit of a 1-byte previous
+// length, so edits keep growing fields and cascading.
+func editValue(r *rand.Rand) any {
+	switch r.Intn(4) {
+	case 0:
+		return int64(r.Intn(1000) - 500)
+	case 1:
+		return strings.Repeat("s", r.Intn(70))
+	}
+	return strings.Repeat("L", 248+r.Intn(9))
+}
+
+func TestEditsAgainstModel(t *testing.T) {
+	for _, compact := range []bool{false, true} {
+		r := rand.New(rand.NewSource(1))
+		zl := New(0)
+		if compact {
+			zl = NewCompact(0)
+		}
+		var model []any
+
+		for range 4000 {
+			switch op := r.Intn(6); {
+			case op == 0 || len(model) == 0:
+				v := editValue(r)
+				if r.Intn(2) == 0 {
+					zl.Push(v)
+					model = append(model, v)
+				} else {
+					zl.PushFront(v)
+					model = slices.Insert(model, 0, v)
+				}
+			case op == 1:
+				i, v := r.Intn(len(model)+1), editValue(r)
+				if err := zl.Insert(i, v); err != nil {
+					t.Fatal(err)
+				}
+				model = slices.Insert(model, i, v)
+			case op <= 3:
+				i, v := r.Intn(len(model)), editValue(r)
+				if err := zl.Set(i, v); err != nil {
+					t.Fatal(err)
+				}
+				model[i] = v
+			default:
+				i := r.Intn(len(model))
+				n := r.Intn(min(3, len(model)-i) + 1)
+				if err := zl.RemoveRange(i, n); err != nil {
+					t.Fatal(err)
+				}
+				model = slices.Delete(model, i, i+n)
+			}
+			checkList(t, zl, model)
+		}
+	}
+}
+
+func TestEditsOutOfRange(t *testing.T) {
+	zl := New(0)
+	for n := range 20 {
+		zl.Push(n)
+	}
+	for _, bad := range [][2]int{{-1, 1}, {0, -1}, {0, 21}, {20, 1}} {
+		if err := zl.RemoveRange(bad[0], bad[1]); err == nil {
+			t.Errorf("RemoveRange(%d, %d) succeeded", bad[0], bad[1])
+		}
+	}
+	if zl.Insert(-1, 1) == nil || zl.Insert(21, 1) == nil || zl.Set(20, 1) == nil {
+		t.Error("out of range edit succeeded")
+	}
+	if zl.Len() != 20 {
+		t.Fatalf("Len() = %d after failed edits", zl.Len())
+	}
+}
+
+func TestCompactEncodings(t *testing.T) {
+	cases := []struct {
+		value any
+		typ   byte
+		size  int // type byte and payload, without the previous length
+		want  any
+	}{
+		{0, TYPE_IMM, 1, int64(0)},
+		{uint8(12), TYPE_IMMMAX, 1, int64(12)},
+		{13, TYPE_CINT8, 2, int64(13)},
+		{int16(-1), TYPE_CINT8, 2, int64(-1)},
+		{math.MinInt8, TYPE_CINT8, 2, int64(math.MinInt8)},
+		{math.MaxInt8 + 1, TYPE_CINT16, 3, int64(math.MaxInt8 + 1)},
+		{math.MinInt16, TYPE_CINT16, 3, int64(math.MinInt16)},
+		{math.MaxInt16 + 1, TYPE_CINT24, 4, int64(math.MaxInt16 + 1)},
+		{-1 << 23, TYPE_CINT24, 4, int64(-1 << 23)},
+		{1<<23 - 1, TYPE_CINT24, 4, int64(1<<23 - 1)},
+		{uint32(1 << 23), TYPE_CINT32, 5, int64(1 << 23)},
+		{math.MinInt32, TYPE_CINT32, 5, int64(math.MinInt32)},
+		{uint64(math.MaxInt32 + 1), TYPE_CINT64, 9, int64(math.MaxInt32 + 1)},
+		{int64(math.MinInt64), TYPE_CINT64, 9, int64(math.MinInt64)},
+		{uint64(math.MaxInt64 + 1), TYPE_UINT64, 9, uint64(math.MaxInt64 + 1)},
+		{2.5, TYPE_FLOAT64, 9, 2.5},
+		{"", TYPE_STRING, 1, ""},
+		{strings.Repeat("a", 63), TYPE_STRING, 1 + 63, strings.Repeat("a", 63)},
+		{strings.Repeat("a", 64), TYPE_STRING, 2 + 64, strings.Repeat("a", 64)},
+		{strings.Repeat("a", 1<<14-1), TYPE_STRING, 2 + 1<<14 - 1, strings.Repeat("a", 1<<14-1)},
+		{strings.Repeat("a", 1<<14), TYPE_STRING, 5 + 1<<14, strings.Repeat("a", 1<<14)},
+	}
+	for _, c := range cases {
+		zl := NewCompact(0)
+		// An empty list is its header and the end marker.
+		first := len(zl.bytes) - 1
+		if err := zl.Push(c.value); err != nil {
+			t.Fatal(err)
+		}
+		prevlen := 1
+		if size := len(zl.bytes) - 1 - first - prevlen; size != c.size {
+			t.Errorf("%T %.20v: %d bytes, want %d", c.value, c.value, size, c.size)
+		}
+		if typ, _, _, _ := zl.layout(first); typ != c.typ {
+			t.Errorf("%T %.20v: type %#x, want %#x", c.value, c.value, typ, c.typ)
+		}
+		if got, _ := zl.At(0); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%T %.20v: At = %#v, want %#v", c.value, c.value, got, c.want)
+		}
+	}
+
+	// Immediates carry their value in the type byte.
+	for n := range 13 {
+		zl := NewCompact(0)
+		first := len(zl.bytes) - 1
+		zl.Push(n)
+		if typ, _, _, _ := zl.layout(first); typ != TYPE_IMM+byte(n) || len(zl.bytes)-1-first != 2 {
+			t.Fatalf("%d: type %#x in %d bytes", n, typ, len(zl.bytes)-1-first)
+		}
+		if got, _ := zl.At(0); got != int64(n) {
+			t.Fatalf("%d: At = %#v", n, got)
+		}
+	}
+}
+
+func TestCascade(t *testing.T) {
+	// Every entry is 253 bytes, just below the limit, so growing the first
+	// previous length field makes every following entry grow in turn.
+	mid := strings.Repeat("m", 250)
+	zl := New(0)
+	var model []any
+	for range 20 {
+		zl.Push(mid)
+		model = append(model, mid)
+	}
+	size := len(zl.bytes)
+
+	big := strings.Repeat("B", 300)
+	zl.PushFront(big)
+	model = slices.Insert(model, 0, any(big))
+	checkList(t, zl, model)
+	// The new entry (prevlen, 2-byte header, payload), plus 4 more bytes for
+	// each of the 20 cascaded fields.
+	if grown := len(zl.bytes) - size; grown != 1+2+300+20*4 {
+		t.Fatalf("list grew by %d bytes", grown)
+	}
+
+	// Shrinking the first entry leaves the 5-byte fields in place.
+	zl.Set(0, "x")
+	model[0] = "x"
+	checkList(t, zl, model)
+	zl.RemoveRange(0, 1)
+	checkList(t, zl, model[1:])
+}
+
+func TestPopAndLast(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	zl := New(0)
+	var model []any
+	for range 3000 {
+		if r.Intn(3) > 0 || len(model) == 0 {
+			v := editValue(r)
+			zl.Push(v)
+			model = append(model, v)
+		} else {
+			got, err := zl.Pop()
+			if err != nil || !reflect.DeepEqual(got, model[len(model)-1]) {
+				t.Fatalf("Pop() = %#v, %v; want %#v", got, err, model[len(model)-1])
+			}
+			model = model[:len(model)-1]
+		}
+		if len(model) > 0 {
+			if got, err := zl.Last(); err != nil || !reflect.DeepEqual(got, model[len(model)-1]) {
+				t.Fatalf("Last() = %#v, %v; want %#v", got, err, model[len(model)-1])
+			}
+		}
+	}
+	checkList(t, zl, model)
+
+	for zl.Len() > 0 {
+		zl.Pop()
+	}
+	checkList(t, zl, nil)
+	if _, err := zl.Pop(); err == nil {
+		t.Error("Pop on an empty list succeeded")
+	}
+	if _, err := zl.Last(); err == nil {
+		t.Error("Last on an empty list succeeded")
+	}
+}
+
+func TestBackwardStops(t *testing.T) {
+	zl := New(0)
+	for n := range 10 {
+		zl.Push(n)
+	}
+	var seen []int
+	for i := range zl.Backward() {
+		if seen = append(seen, i); len(seen) == 3 {
+			break
+		}
+	}
+	if !slices.Equal(seen, []int{9, 8, 7}) {
+		t.Fatalf("Backward yielded %v", seen)
+	}
+}
+
+func TestAppendRange(t *testing.T) {
+	// Entries of 254 bytes and more need a 5-byte previous length in the
+	// entry after them, which AppendRange has to rewrite.
+	big := strings.Repeat("b", 300)
+	src := NewCompact(0)
+	for _, v := range []any{"a", big, int64(7), int64(1 << 40), big, "z", 2.5} {
+		src.Push(v)
+	}
+	all := values(src)
+
+	for start := 0; start <= src.Len(); start++ {
+		for n := 0; start+n <= src.Len(); n++ {
+			for _, prefix := range []any{nil, "x", big} {
+				dst := NewCompact(0)
+				var want []any
+				if prefix != nil {
+					dst.Push(prefix)
+					want = append(want, prefix)
+				}
+				if err := dst.AppendRange(src, start, n); err != nil {
+					t.Fatal(err)
+				}
+				checkList(t, dst, append(want, all[start:start+n]...))
+
+				// Compact integers are copied with their width.
+				for i := range n {
+					got, _ := dst.entry(len(want) + i)
+					e, _ := src.entry(start + i)
+					if got.Type() != e.Type() {
+						t.Fatalf("entry %d: type %#x, want %#x", i, got.Type(), e.Type())
+					}
+				}
+			}
+		}
+	}
+	checkList(t, src, all)
+
+	// With spare capacity the copy lands in the array it reads from.
+	self := NewCompact(4096)
+	self.AppendRange(src, 0, src.Len())
+	if err := self.AppendRange(self, 1, 4); err != nil {
+		t.Fatal(err)
+	}
+	checkList(t, self, append(all, all[1:5]...))
+
+	for _, r := range [][2]int{{-1, 1}, {0, -1}, {0, src.Len() + 1}, {src.Len(), 1}} {
+		if err := New(0).AppendRange(src, r[0], r[1]); err == nil {
+			t.Errorf("AppendRange(%d, %d) succeeded", r[0], r[1])
+		}
+	}
+}